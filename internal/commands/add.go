@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+	"github.com/dastanaron/bookmarks/internal/repository"
+	"github.com/dastanaron/bookmarks/internal/service"
+)
+
+// AddOptions describes a bookmark to add from the command line.
+// Empty fields are left unchanged when the URL is already bookmarked.
+type AddOptions struct {
+	URL         string
+	Title       string
+	Description string
+	Folder      string // slash-separated folder path, created if missing
+	Tags        []string
+	ReadLater   *bool // mark (true) or unmark (false) to read later, nil to keep
+}
+
+// AddCommand adds a single bookmark without starting the TUI
+type AddCommand struct {
+	repo repository.Repository
+}
+
+// NewAddCommand creates a new add command
+func NewAddCommand(repo repository.Repository) *AddCommand {
+	return &AddCommand{repo: repo}
+}
+
+// Execute adds the bookmark, or updates it if the URL already exists,
+// and prints its ID so scripts can pick it up. It runs in a single
+// transaction, so a failed save leaves no new folders behind.
+func (c *AddCommand) Execute(opts AddOptions) error {
+	url := strings.TrimSpace(opts.URL)
+	if url == "" {
+		return fmt.Errorf("URL is required")
+	}
+
+	var b models.Bookmark
+	err := c.repo.Transaction(func(repo repository.Repository) error {
+		bookmarkSvc := service.NewBookmarkService(repo)
+		existing, err := bookmarkSvc.GetByURL(url)
+		if err != nil {
+			return fmt.Errorf("failed to look up bookmark: %w", err)
+		}
+
+		b = models.Bookmark{URL: url, Title: url}
+		if existing != nil {
+			b = *existing
+			b.UpdatedAt = time.Time{} // let Upsert stamp the update
+		}
+		if opts.Title != "" {
+			b.Title = opts.Title
+		}
+		if opts.Description != "" {
+			b.Description = opts.Description
+		}
+		if opts.Folder != "" {
+			folder, err := service.NewFolderService(repo).UpsertPath(opts.Folder)
+			if err != nil {
+				return fmt.Errorf("failed to create folder: %w", err)
+			}
+			b.FolderID = nil
+			if folder != nil {
+				b.FolderID = &folder.ID
+			}
+		}
+		if opts.ReadLater != nil {
+			b.ReadLater = *opts.ReadLater
+		}
+		// Tags are added to the existing ones, never removed
+		b.Tags = append(append([]string{}, b.Tags...), opts.Tags...)
+
+		if _, err := bookmarkSvc.Upsert(&b); err != nil {
+			return fmt.Errorf("failed to save bookmark: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(b.ID)
+	return nil
+}
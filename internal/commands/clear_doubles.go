@@ -33,6 +33,7 @@ func (c *ClearDoublesCommand) Execute() error {
 	// Track seen URLs and duplicates to delete
 	seenURLs := make(map[string]int) // URL -> ID of bookmark to keep
 	duplicatesToDelete := make([]int, 0)
+	tagsToKeep := make(map[int][]string) // ID of kept bookmark -> tags of its duplicates
 
 	for _, bookmark := range allBookmarks {
 		if bookmark.URL == "" {
@@ -43,6 +44,7 @@ func (c *ClearDoublesCommand) Execute() error {
 		if existingID, exists := seenURLs[bookmark.URL]; exists {
 			// This is a duplicate - mark for deletion
 			duplicatesToDelete = append(duplicatesToDelete, bookmark.ID)
+			tagsToKeep[existingID] = append(tagsToKeep[existingID], bookmark.Tags...)
 			fmt.Printf("Found duplicate: '%s' (ID: %d, keeping ID: %d)\n", bookmark.Title, bookmark.ID, existingID)
 		} else {
 			// First occurrence of this URL - keep it
@@ -55,6 +57,16 @@ func (c *ClearDoublesCommand) Execute() error {
 		return nil
 	}
 
+	// Carry tags of duplicates over to the bookmark we keep
+	for id, tags := range tagsToKeep {
+		if len(tags) == 0 {
+			continue
+		}
+		if err := c.bookmarkSvc.AddTags(id, tags); err != nil {
+			fmt.Printf("Warning: failed to merge tags into bookmark ID %d: %v\n", id, err)
+		}
+	}
+
 	// Delete duplicates
 	deleted := 0
 	for _, id := range duplicatesToDelete {
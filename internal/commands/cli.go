@@ -0,0 +1,273 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dastanaron/bookmarks/internal/config"
+	"github.com/dastanaron/bookmarks/internal/repository"
+)
+
+// Exit codes returned by CLI.Run
+const (
+	ExitOK    = 0
+	ExitError = 1 // the command failed
+	ExitUsage = 2 // invalid command line
+)
+
+// Command is a subcommand of the command line interface
+type Command interface {
+	// Name is the word that selects the command, e.g. "import"
+	Name() string
+	// Synopsis describes the arguments, e.g. "<file> [flags]"
+	Synopsis() string
+	// Summary is a one-line description for help output
+	Summary() string
+	// Run parses the command's arguments and executes it
+	Run(env *Env, args []string) error
+}
+
+// Env is shared by the commands of one CLI run
+type Env struct {
+	Program string
+	Config  *config.Config
+	Stderr  io.Writer
+	repo    *repository.SQLiteRepository
+}
+
+// Repository opens the database on first use, creating its directory if needed
+func (e *Env) Repository() (repository.Repository, error) {
+	if e.repo != nil {
+		return e.repo, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(e.Config.DBPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+	repo, err := repository.NewSQLiteRepository(e.Config.DBPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	e.repo = repo
+	return repo, nil
+}
+
+// Close closes the database if it was opened
+func (e *Env) Close() error {
+	if e.repo == nil {
+		return nil
+	}
+	return e.repo.Close()
+}
+
+// UsageError reports invalid command line arguments
+type UsageError struct {
+	Command  string // empty for global flags
+	Msg      string
+	reported bool // already printed by the flag package together with the usage
+}
+
+func (e *UsageError) Error() string {
+	return e.Msg
+}
+
+func usageErrorf(cmd Command, format string, args ...interface{}) error {
+	return &UsageError{Command: cmd.Name(), Msg: fmt.Sprintf(format, args...)}
+}
+
+// CLI dispatches command line arguments to commands
+type CLI struct {
+	program  string
+	config   *config.Config
+	commands []Command
+	stderr   io.Writer
+}
+
+// NewCLI creates the command line interface with all commands
+func NewCLI(program string, cfg *config.Config) *CLI {
+	return &CLI{
+		program: program,
+		config:  cfg,
+		commands: []Command{
+			&tuiCLI{},
+			&addCLI{},
+			&listCLI{name: "list"},
+			&listCLI{name: "search"},
+			&removeCLI{},
+			&moveCLI{},
+			&folderCLI{},
+			&importCLI{},
+			&exportCLI{},
+			&siteCLI{},
+			&dedupeCLI{},
+			&purgeCLI{},
+			&migrateStatusCLI{},
+		},
+		stderr: os.Stderr,
+	}
+}
+
+// Run executes the command selected by args (without the program name)
+// and returns the process exit code. Without a command it starts the TUI.
+func (c *CLI) Run(args []string) int {
+	env := &Env{Program: c.program, Config: c.config, Stderr: c.stderr}
+	defer env.Close()
+
+	err := c.run(env, args)
+	var usageErr *UsageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case errors.As(err, &usageErr):
+		if usageErr.reported {
+			return ExitUsage
+		}
+		fmt.Fprintf(c.stderr, "Error: %v\n", err)
+		if usageErr.Command != "" {
+			fmt.Fprintf(c.stderr, "Run '%s help %s' for usage.\n", c.program, usageErr.Command)
+		} else {
+			fmt.Fprintf(c.stderr, "Run '%s help' for usage.\n", c.program)
+		}
+		return ExitUsage
+	default:
+		fmt.Fprintf(c.stderr, "Error: %v\n", err)
+		return ExitError
+	}
+}
+
+func (c *CLI) run(env *Env, args []string) error {
+	fs := flag.NewFlagSet(c.program, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.StringVar(&c.config.DBPath, "db", c.config.DBPath, "Path to database file")
+	legacy := registerLegacyFlags(fs)
+	fs.Usage = c.printUsage
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &UsageError{Msg: err.Error(), reported: true}
+	}
+	args = fs.Args()
+
+	legacyArgs, err := legacy.commandArgs(fs, c.stderr, c.program)
+	if err != nil {
+		return err
+	}
+	if legacyArgs != nil {
+		if len(args) > 0 {
+			return &UsageError{Msg: fmt.Sprintf("deprecated flags can't be combined with the %q command", args[0])}
+		}
+		args = legacyArgs
+	}
+
+	if len(args) == 0 {
+		args = []string{"tui"}
+	}
+	name, args := args[0], args[1:]
+
+	if name == "help" {
+		return c.help(env, args)
+	}
+	cmd := c.find(name)
+	if cmd == nil {
+		return &UsageError{Msg: fmt.Sprintf("unknown command %q", name)}
+	}
+	return cmd.Run(env, args)
+}
+
+func (c *CLI) find(name string) Command {
+	for _, cmd := range c.commands {
+		if cmd.Name() == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// help prints the overview, or the usage of a single command
+func (c *CLI) help(env *Env, args []string) error {
+	if len(args) == 0 {
+		c.printUsage()
+		return nil
+	}
+	cmd := c.find(args[0])
+	if cmd == nil {
+		return &UsageError{Msg: fmt.Sprintf("unknown command %q", args[0])}
+	}
+	return cmd.Run(env, []string{"-h"})
+}
+
+func (c *CLI) printUsage() {
+	w := c.stderr
+	fmt.Fprintf(w, "Usage:\n  %s [--db path] [command] [arguments]\n\n", c.program)
+	fmt.Fprintf(w, "Without a command the terminal UI is started.\n\nCommands:\n")
+	for _, cmd := range c.commands {
+		fmt.Fprintf(w, "  %-15s %s\n", cmd.Name(), cmd.Summary())
+	}
+	fmt.Fprintf(w, "  %-15s %s\n", "help", "Show help for a command")
+	fmt.Fprintf(w, "\nGlobal flags:\n  --db path       Path to database file (default: %s)\n", c.config.DBPath)
+	fmt.Fprintf(w, "\nRun '%s help <command>' for the arguments and flags of a command.\n", c.program)
+	fmt.Fprintf(w, "The old flags (--import, --export, --clear-doubles, ...) still work but are deprecated.\n")
+}
+
+// newFlagSet creates the flag set of a command, printing its usage on -h
+func newFlagSet(env *Env, cmd Command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.Stderr, "Usage: %s %s %s\n\n%s\n", env.Program, cmd.Name(), cmd.Synopsis(), cmd.Summary())
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintf(env.Stderr, "\nFlags:\n")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+// parseArgs parses flags that may appear before, between or after
+// positional arguments, and returns the positional arguments
+func parseArgs(cmd Command, fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &UsageError{Command: cmd.Name(), Msg: err.Error(), reported: true}
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// stringList is a flag that can be given several times
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// flagIfSet returns a pointer to value if the flag was given, nil otherwise
+func flagIfSet(fs *flag.FlagSet, name string, value bool) *bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) { set = set || f.Name == name })
+	if !set {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+	"github.com/dastanaron/bookmarks/internal/repository"
+	"github.com/dastanaron/bookmarks/internal/service"
+)
+
+// DeleteFolderCommand moves a folder to the trash
+type DeleteFolderCommand struct {
+	repo      repository.Repository
+	folderSvc *service.FolderService
+}
+
+// NewDeleteFolderCommand creates a new delete folder command
+func NewDeleteFolderCommand(repo repository.Repository) *DeleteFolderCommand {
+	return &DeleteFolderCommand{
+		repo:      repo,
+		folderSvc: service.NewFolderService(repo),
+	}
+}
+
+// Execute moves folder id to the trash. policy ("move" or "cascade") decides
+// what happens to its contents; it may only be empty for an empty folder.
+func (c *DeleteFolderCommand) Execute(id int, policy string) error {
+	folder, err := c.folderSvc.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to get folder: %w", err)
+	}
+	if folder == nil || !folder.DeletedAt.IsZero() {
+		return fmt.Errorf("folder %d not found", id)
+	}
+
+	bookmarks, folders, err := c.folderSvc.CountContents(id)
+	if err != nil {
+		return fmt.Errorf("failed to count folder contents: %w", err)
+	}
+
+	if policy == "" {
+		if bookmarks > 0 || folders > 0 {
+			return fmt.Errorf("folder '%s' contains %d bookmarks and %d subfolders, use --policy move or cascade",
+				folder.Name, bookmarks, folders)
+		}
+		policy = "cascade"
+	}
+	p, err := service.ParseFolderDeletePolicy(policy)
+	if err != nil {
+		return err
+	}
+
+	if err := c.folderSvc.Delete(id, p); err != nil {
+		return fmt.Errorf("failed to delete folder: %w", err)
+	}
+
+	if p == models.FolderDeleteMoveToParent {
+		fmt.Printf("Moved folder '%s' to the trash, its contents (%d bookmarks, %d subfolders) moved to the parent folder\n", folder.Name, bookmarks, folders)
+	} else {
+		fmt.Printf("Moved folder '%s' to the trash with %d bookmarks and %d subfolders\n", folder.Name, bookmarks, folders)
+	}
+	return nil
+}
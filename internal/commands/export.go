@@ -2,167 +2,154 @@ package commands
 
 import (
 	"fmt"
-	"html"
 	"os"
-	"sort"
+	"strings"
 
+	"github.com/dastanaron/bookmarks/internal/exporter"
 	"github.com/dastanaron/bookmarks/internal/models"
 	"github.com/dastanaron/bookmarks/internal/repository"
 	"github.com/dastanaron/bookmarks/internal/service"
 )
 
-// ExportCommand handles bookmark export to HTML file
+// ExportFormats returns the file formats written by ExportCommand; "auto"
+// picks the format from the file name extension, defaulting to HTML
+func ExportFormats() []string {
+	return append([]string{"auto"}, exporter.DefaultRegistry().Names()...)
+}
+
+// ExportOptions selects the file to export to and how
+type ExportOptions struct {
+	Path   string // output file, or directory with PerFolder
+	Format string // one of ExportFormats, empty for "auto"
+	// PerFolder writes one file per folder into the directory Path, for
+	// formats that support it (markdown)
+	PerFolder   bool
+	FrontMatter bool // add YAML front matter (markdown only)
+	// Folder limits the export to a folder (ID or slash-separated path) and
+	// its subfolders; the folder becomes the root of the file
+	Folder string
+	// Query limits the export to the bookmarks matching a search query, in
+	// the folders that contain any of them
+	Query string
+}
+
+// ExportCommand handles bookmark export to a file
 type ExportCommand struct {
 	repo        repository.Repository
 	bookmarkSvc *service.BookmarkService
 	folderSvc   *service.FolderService
+	exporters   *exporter.Registry
 }
 
 // NewExportCommand creates a new export command
 func NewExportCommand(repo repository.Repository) *ExportCommand {
-	bookmarkSvc := service.NewBookmarkService(repo)
-	folderSvc := service.NewFolderService(repo)
 	return &ExportCommand{
 		repo:        repo,
-		bookmarkSvc: bookmarkSvc,
-		folderSvc:   folderSvc,
+		bookmarkSvc: service.NewBookmarkService(repo),
+		folderSvc:   service.NewFolderService(repo),
+		exporters:   exporter.DefaultRegistry(),
 	}
 }
 
-// Execute exports bookmarks to HTML file
-func (c *ExportCommand) Execute(filePath string) error {
-	// Get all folders and bookmarks
-	folders, err := c.folderSvc.ListAll()
+// Execute exports the bookmarks selected by opts (all by default) to a file,
+// or to a directory in per-folder mode
+func (c *ExportCommand) Execute(opts ExportOptions) error {
+	exp, err := c.exporter(opts)
 	if err != nil {
-		return fmt.Errorf("failed to get folders: %w", err)
+		return err
 	}
 
-	bookmarks, err := c.bookmarkSvc.ListAll()
+	root, err := c.tree(opts)
 	if err != nil {
-		return fmt.Errorf("failed to get bookmarks: %w", err)
+		return err
 	}
 
-	// Create file
-	file, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("cannot create file: %w", err)
-	}
-	defer file.Close()
-
-	// Write HTML header
-	fmt.Fprintf(file, "<!DOCTYPE NETSCAPE-Bookmark-file-1>\n")
-	fmt.Fprintf(file, "<META HTTP-EQUIV=\"Content-Type\" CONTENT=\"text/html; charset=UTF-8\">\n")
-	fmt.Fprintf(file, "<TITLE>Bookmarks</TITLE>\n")
-	fmt.Fprintf(file, "<H1>Bookmarks</H1>\n")
-	fmt.Fprintf(file, "<DL><p>\n")
-
-	// Build folder hierarchy
-	folderMap := make(map[int]*models.Folder)
-	for i := range folders {
-		folderMap[folders[i].ID] = &folders[i]
-	}
-
-	// Group bookmarks by folder ID (use -1 for nil folder)
-	bookmarksByFolder := make(map[int][]models.Bookmark)
-	for i := range bookmarks {
-		b := &bookmarks[i]
-		var folderKey int = -1 // -1 represents nil folder
-		if b.FolderID != nil {
-			folderKey = *b.FolderID
+	if opts.PerFolder {
+		dirExp, ok := exp.(exporter.DirExporter)
+		if !ok {
+			return fmt.Errorf("the %s format cannot write one file per folder", exp.Name())
 		}
-		bookmarksByFolder[folderKey] = append(bookmarksByFolder[folderKey], *b)
-	}
-
-	// Sort bookmarks in each folder by title
-	for folderID := range bookmarksByFolder {
-		sort.Slice(bookmarksByFolder[folderID], func(i, j int) bool {
-			return bookmarksByFolder[folderID][i].Title < bookmarksByFolder[folderID][j].Title
-		})
-	}
-
-	// Write root bookmarks (without folder)
-	if rootBookmarks, ok := bookmarksByFolder[-1]; ok {
-		for _, b := range rootBookmarks {
-			c.writeBookmark(file, &b)
+		if err := dirExp.ExportDir(opts.Path, root); err != nil {
+			return fmt.Errorf("failed to write %s: %w", opts.Path, err)
 		}
+	} else if err := c.writeFile(exp, opts.Path, root); err != nil {
+		return err
 	}
 
-	// Write folders recursively
-	rootFolders := c.getRootFolders(folders)
-	for _, folder := range rootFolders {
-		c.writeFolder(file, folder, folders, folderMap, bookmarksByFolder, 0)
-	}
-
-	// Write HTML footer
-	fmt.Fprintf(file, "</DL><p>\n")
-
-	fmt.Printf("Exported %d bookmarks to %s\n", len(bookmarks), filePath)
+	_, count := root.Count()
+	fmt.Printf("Exported %d bookmarks to %s\n", count, opts.Path)
 	return nil
 }
 
-// getRootFolders returns folders without parent or with parent_id = 0
-func (c *ExportCommand) getRootFolders(folders []models.Folder) []models.Folder {
-	var rootFolders []models.Folder
-	for _, f := range folders {
-		if f.ParentID == nil || *f.ParentID == 0 {
-			rootFolders = append(rootFolders, f)
-		}
+// tree returns the folder tree selected by opts.Folder and opts.Query
+func (c *ExportCommand) tree(opts ExportOptions) (*models.FolderNode, error) {
+	root, err := c.folderSvc.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookmarks: %w", err)
 	}
-	// Sort by name
-	sort.Slice(rootFolders, func(i, j int) bool {
-		return rootFolders[i].Name < rootFolders[j].Name
-	})
-	return rootFolders
-}
-
-// writeFolder writes a folder and its contents recursively
-func (c *ExportCommand) writeFolder(file *os.File, folder models.Folder, allFolders []models.Folder, folderMap map[int]*models.Folder, bookmarksByFolder map[int][]models.Bookmark, indent int) {
-	// Write folder header
-	fmt.Fprintf(file, "    <DT><H3>%s</H3>\n", html.EscapeString(folder.Name))
-	fmt.Fprintf(file, "    <DL><p>\n")
 
-	// Write bookmarks in this folder
-	if folderBookmarks, ok := bookmarksByFolder[folder.ID]; ok {
-		for _, b := range folderBookmarks {
-			c.writeBookmark(file, &b)
+	if opts.Folder != "" {
+		node, err := folderSubtree(c.folderSvc, root, opts.Folder)
+		if err != nil {
+			return nil, err
 		}
+		// The contents of the folder go to the top level of the file
+		root = &models.FolderNode{Folders: node.Folders, Bookmarks: node.Bookmarks}
 	}
 
-	// Write child folders
-	childFolders := c.getChildFolders(folder.ID, allFolders)
-	for _, child := range childFolders {
-		c.writeFolder(file, child, allFolders, folderMap, bookmarksByFolder, indent+1)
+	if opts.Query != "" {
+		matches, err := c.bookmarkSvc.Search(opts.Query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search bookmarks: %w", err)
+		}
+		ids := make(map[int]bool, len(matches))
+		for _, b := range matches {
+			ids[b.ID] = true
+		}
+		root = root.Filter(func(b *models.Bookmark) bool { return ids[b.ID] })
 	}
-
-	// Close folder
-	fmt.Fprintf(file, "    </DL><p>\n")
+	return root, nil
 }
 
-// getChildFolders returns child folders of a given parent
-func (c *ExportCommand) getChildFolders(parentID int, allFolders []models.Folder) []models.Folder {
-	var children []models.Folder
-	for _, f := range allFolders {
-		if f.ParentID != nil && *f.ParentID == parentID {
-			children = append(children, f)
+// exporter returns the exporter for opts, configured with its options
+func (c *ExportCommand) exporter(opts ExportOptions) (exporter.Exporter, error) {
+	var exp exporter.Exporter
+	if opts.Format == "" || opts.Format == "auto" {
+		exp = c.exporters.ForFile(opts.Path)
+		if exp == nil && opts.PerFolder {
+			// A directory has no extension; Markdown is the format written per folder
+			exp = c.exporters.Get("markdown")
+		} else if exp == nil {
+			exp = c.exporters.Get("html")
+		}
+	} else if exp = c.exporters.Get(opts.Format); exp == nil {
+		return nil, fmt.Errorf("unknown export format %q (expected one of: %s)", opts.Format, strings.Join(ExportFormats(), ", "))
+	}
+
+	if opts.FrontMatter {
+		md, ok := exp.(*exporter.MarkdownExporter)
+		if !ok {
+			return nil, fmt.Errorf("front matter is only supported by the markdown format, not %s", exp.Name())
 		}
+		withFrontMatter := *md
+		withFrontMatter.FrontMatter = true
+		exp = &withFrontMatter
 	}
-	// Sort by name
-	sort.Slice(children, func(i, j int) bool {
-		return children[i].Name < children[j].Name
-	})
-	return children
+	return exp, nil
 }
 
-// writeBookmark writes a single bookmark
-func (c *ExportCommand) writeBookmark(file *os.File, b *models.Bookmark) {
-	escapedURL := html.EscapeString(b.URL)
-	escapedTitle := html.EscapeString(b.Title)
-	
-	// Write bookmark with icon if available
-	if b.Icon != nil && *b.Icon != "" {
-		escapedIcon := html.EscapeString(*b.Icon)
-		fmt.Fprintf(file, "    <DT><A HREF=\"%s\" ICON=\"%s\">%s</A>\n", escapedURL, escapedIcon, escapedTitle)
-	} else {
-		fmt.Fprintf(file, "    <DT><A HREF=\"%s\">%s</A>\n", escapedURL, escapedTitle)
+// writeFile exports root to a single file
+func (c *ExportCommand) writeFile(exp exporter.Exporter, filePath string, root *models.FolderNode) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("cannot create file: %w", err)
+	}
+	if err := exp.Export(file, root); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
 	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	return nil
 }
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+	"github.com/dastanaron/bookmarks/internal/repository"
+	"github.com/dastanaron/bookmarks/internal/service"
+)
+
+func TestHTMLRoundTrip(t *testing.T) {
+	src := newTestRepo(t)
+	at := func(day int) time.Time { return time.Date(2024, 3, day, 10, 30, 15, 0, time.UTC) }
+
+	toolbar := &models.Folder{Name: "Bookmarks bar", Toolbar: true, CreatedAt: at(1), UpdatedAt: at(2)}
+	if err := src.Folders().Insert(toolbar); err != nil {
+		t.Fatal(err)
+	}
+	golang := &models.Folder{Name: "Go & tools", ParentID: &toolbar.ID, CreatedAt: at(3), UpdatedAt: at(4)}
+	if err := src.Folders().Insert(golang); err != nil {
+		t.Fatal(err)
+	}
+	icon := "data:image/png;base64,aWNvbg=="
+	for _, b := range []*models.Bookmark{
+		{
+			Title:         "The Go <Playground>",
+			URL:           "https://go.dev/play/?v=1&x=2",
+			Description:   "Run \"Go\" code\nin the browser",
+			Keyword:       "play",
+			Icon:          &icon,
+			FolderID:      &golang.ID,
+			Tags:          []string{"go", "tools"},
+			CreatedAt:     at(5),
+			UpdatedAt:     at(6),
+			LastVisitedAt: at(7),
+		},
+		{Title: "Wikipedia", URL: "https://en.wikipedia.org/", Keyword: "w", FolderID: &toolbar.ID, Tags: []string{}, CreatedAt: at(8)},
+		{Title: "Example", URL: "https://example.com/", Tags: []string{}},
+	} {
+		if err := src.Bookmarks().Create(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "bookmarks.html")
+	if err := NewExportCommand(src).Execute(ExportOptions{Path: path, Format: "html"}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	dst := newTestRepo(t)
+	if err := NewImportCommand(dst).Execute(ImportOptions{Path: path}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	want := exportedTree(t, src)
+	got := exportedTree(t, dst)
+	if len(got) != len(want) {
+		t.Fatalf("after the round trip\n got %q\nwant %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("after the round trip\n got %s\nwant %s", got[i], want[i])
+		}
+	}
+}
+
+// exportedTree lists the folders and bookmarks of repo, one per line with
+// their path, without the fields that Netscape files don't carry: IDs,
+// parent references and the read-later flag
+func exportedTree(t *testing.T, repo repository.Repository) []string {
+	t.Helper()
+	root, err := service.NewFolderService(repo).Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	var walk func(n *models.FolderNode, path string)
+	walk = func(n *models.FolderNode, path string) {
+		for _, b := range n.Bookmarks {
+			b.ID, b.FolderID, b.FolderName, b.ReadLater = 0, nil, nil, false
+			b.CreatedAt, b.UpdatedAt, b.LastVisitedAt = b.CreatedAt.UTC(), b.UpdatedAt.UTC(), b.LastVisitedAt.UTC()
+			icon := ""
+			if b.Icon != nil {
+				icon, b.Icon = *b.Icon, nil
+			}
+			lines = append(lines, fmt.Sprintf("%s: %+v icon=%q", path, b, icon))
+		}
+		for _, child := range n.Folders {
+			f := child.Folder
+			childPath := path + "/" + f.Name
+			lines = append(lines, fmt.Sprintf("%s: toolbar=%v created=%v updated=%v",
+				childPath, f.Toolbar, f.CreatedAt.UTC(), f.UpdatedAt.UTC()))
+			walk(child, childPath)
+		}
+	}
+	walk(root, "")
+	return lines
+}
@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+	"github.com/dastanaron/bookmarks/internal/repository"
+	"github.com/dastanaron/bookmarks/internal/service"
+)
+
+// FolderCommand manages folders from the command line
+type FolderCommand struct {
+	repo      repository.Repository
+	folderSvc *service.FolderService
+}
+
+// NewFolderCommand creates a new folder command
+func NewFolderCommand(repo repository.Repository) *FolderCommand {
+	return &FolderCommand{
+		repo:      repo,
+		folderSvc: service.NewFolderService(repo),
+	}
+}
+
+// List prints the ID and path of every folder, sorted by path
+func (c *FolderCommand) List() error {
+	paths, err := c.folderSvc.Paths()
+	if err != nil {
+		return fmt.Errorf("failed to get folders: %w", err)
+	}
+
+	ids := make([]int, 0, len(paths))
+	for id := range paths {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return paths[ids[i]] < paths[ids[j]] })
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPATH")
+	for _, id := range ids {
+		fmt.Fprintf(w, "%d\t%s\n", id, paths[id])
+	}
+	return w.Flush()
+}
+
+// Add creates the folder at path, including missing parents, and prints its ID
+func (c *FolderCommand) Add(path string) error {
+	folder, err := c.folderSvc.UpsertPath(path)
+	if err != nil {
+		return fmt.Errorf("failed to create folder: %w", err)
+	}
+	if folder == nil {
+		return fmt.Errorf("folder path is empty")
+	}
+	fmt.Println(folder.ID)
+	return nil
+}
+
+// Delete moves the folder given by ID or path to the trash, see DeleteFolderCommand.
+// Unlike resolveFolder, a number is always an ID, so a mistyped ID fails instead
+// of deleting a folder with that name; such a folder is given as "/2023".
+func (c *FolderCommand) Delete(ref string, policy string) error {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return NewDeleteFolderCommand(c.repo).Execute(id, policy)
+	}
+	folder, err := resolveFolder(c.folderSvc, ref)
+	if err != nil {
+		return err
+	}
+	return NewDeleteFolderCommand(c.repo).Execute(folder.ID, policy)
+}
+
+// resolveFolder returns the folder given by ID or slash-separated path,
+// skipping trashed ones. A number is taken as an ID if a folder has it and
+// as a path otherwise, so a folder named "2023" can be given by name.
+func resolveFolder(folderSvc *service.FolderService, ref string) (*models.Folder, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		folder, err := folderSvc.GetByID(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get folder %d: %w", id, err)
+		}
+		if folder != nil && folder.DeletedAt.IsZero() {
+			return folder, nil
+		}
+	}
+
+	folder, err := folderSvc.FindPath(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folders: %w", err)
+	}
+	if folder == nil {
+		return nil, fmt.Errorf("folder %q not found", ref)
+	}
+	return folder, nil
+}
+
+// folderSubtree returns the node in root of the folder given by ID or path
+func folderSubtree(folderSvc *service.FolderService, root *models.FolderNode, ref string) (*models.FolderNode, error) {
+	folder, err := resolveFolder(folderSvc, ref)
+	if err != nil {
+		return nil, err
+	}
+	// The tree only has folders that are not in the trash
+	node := root.FindFolder(folder.ID)
+	if node == nil {
+		return nil, fmt.Errorf("folder %q not found", ref)
+	}
+	return node, nil
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/dastanaron/bookmarks/internal/service"
+)
+
+func TestFolderDeleteByNumber(t *testing.T) {
+	repo := newTestRepo(t)
+	folderSvc := service.NewFolderService(repo)
+	named, err := folderSvc.UpsertPath("99")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if named.ID == 99 {
+		t.Fatal("the folder named 99 must not have the ID 99")
+	}
+	cmd := NewFolderCommand(repo)
+
+	// A number that is no folder ID fails instead of deleting the folder named so
+	if err := cmd.Delete("99", ""); err == nil {
+		t.Error("folder rm 99 succeeded without a folder with ID 99")
+	}
+	if f, err := folderSvc.GetByID(named.ID); err != nil || f == nil || !f.DeletedAt.IsZero() {
+		t.Fatalf("folder named 99 = %+v, %v, want it kept", f, err)
+	}
+
+	// A leading slash gives it by name
+	if err := cmd.Delete("/99", ""); err != nil {
+		t.Fatal(err)
+	}
+	if f, err := folderSvc.GetByID(named.ID); err != nil || f == nil || f.DeletedAt.IsZero() {
+		t.Errorf("folder named 99 = %+v, %v, want it in the trash", f, err)
+	}
+
+	// And its ID works as well
+	other, err := folderSvc.UpsertPath("Other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Delete(strconv.Itoa(other.ID), ""); err != nil {
+		t.Fatal(err)
+	}
+	if f, err := folderSvc.GetByID(other.ID); err != nil || f == nil || f.DeletedAt.IsZero() {
+		t.Errorf("folder Other = %+v, %v, want it in the trash", f, err)
+	}
+}
@@ -1,60 +1,446 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
 
 	"github.com/dastanaron/bookmarks/internal/parser"
 	"github.com/dastanaron/bookmarks/internal/repository"
 	"github.com/dastanaron/bookmarks/internal/service"
 )
 
-// ImportCommand handles bookmark import from HTML file
+// ImportFormats returns the file formats accepted by ImportCommand; "auto"
+// detects the format from the file content
+func ImportFormats() []string {
+	return append([]string{"auto"}, parser.DefaultRegistry().Names()...)
+}
+
+// ImportOptions selects the file to import and how
+type ImportOptions struct {
+	Path   string // bookmarks file, a Firefox profile directory or "-" for stdin
+	Format string // one of ImportFormats, empty for "auto"
+	DryRun bool   // only report what would change
+	// OnConflict decides what happens to bookmarks whose URL already exists
+	OnConflict models.ImportConflictPolicy
+	// Columns maps CSV columns to fields; nil reads them from the header row
+	Columns parser.CSVColumns
+	// TagFolders turns the first tag of each bookmark into its folder, for
+	// formats that only have tags (pinboard, delicious, buku)
+	TagFolders bool
+	// Folder is the slash-separated path of the folder to import into,
+	// created if missing; empty for the root
+	Folder string
+}
+
+// ImportCommand handles bookmark import from a file
 type ImportCommand struct {
-	repo        repository.Repository
-	bookmarkSvc *service.BookmarkService
-	parser      *parser.Parser
+	repo      repository.Repository
+	importers *parser.Registry
 }
 
 // NewImportCommand creates a new import command
 func NewImportCommand(repo repository.Repository) *ImportCommand {
-	folderSvc := service.NewFolderService(repo)
-	bookmarkSvc := service.NewBookmarkService(repo)
 	return &ImportCommand{
-		repo:        repo,
-		bookmarkSvc: bookmarkSvc,
-		parser:      parser.NewParser(folderSvc),
+		repo:      repo,
+		importers: parser.DefaultRegistry(),
+	}
+}
+
+// errDryRun rolls back the transaction of a dry run
+var errDryRun = errors.New("dry run")
+
+// importer returns the importer for opts, configured with its options
+func (c *ImportCommand) importer(opts ImportOptions, filePath string) (parser.Importer, error) {
+	var importer parser.Importer
+	if opts.Format == "" || opts.Format == "auto" {
+		var err error
+		importer, err = c.importers.DetectFile(filePath)
+		if errors.Is(err, parser.ErrUnknownFormat) && opts.Columns != nil {
+			// A column mapping only makes sense for CSV, e.g. without URLs in the first row
+			importer, err = c.importers.Get("csv"), nil
+		}
+		if errors.Is(err, parser.ErrUnknownFormat) {
+			name := filePath
+			if opts.Path == "-" {
+				name = "stdin"
+			}
+			return nil, fmt.Errorf("cannot detect the format of %s, set it with --format (one of: %s)",
+				name, strings.Join(c.importers.Names(), ", "))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot open file: %w", err)
+		}
+	} else if importer = c.importers.Get(opts.Format); importer == nil {
+		return nil, fmt.Errorf("unknown import format %q (expected one of: %s)", opts.Format, strings.Join(ImportFormats(), ", "))
+	}
+
+	if opts.Columns != nil {
+		csvImporter, ok := importer.(*parser.CSVImporter)
+		if !ok {
+			return nil, fmt.Errorf("--columns is only supported by the csv format, not %s", importer.Name())
+		}
+		withColumns := *csvImporter
+		withColumns.Columns = opts.Columns
+		importer = &withColumns
+	}
+	if opts.TagFolders {
+		switch imp := importer.(type) {
+		case *parser.PinboardImporter:
+			withFolders := *imp
+			withFolders.TagFolders = true
+			importer = &withFolders
+		case *parser.DeliciousImporter:
+			withFolders := *imp
+			withFolders.TagFolders = true
+			importer = &withFolders
+		case *parser.BukuImporter:
+			withFolders := *imp
+			withFolders.TagFolders = true
+			importer = &withFolders
+		default:
+			return nil, fmt.Errorf("--tag-mode folder is only supported by the pinboard, delicious and buku formats, not %s", importer.Name())
+		}
+	}
+	return importer, nil
+}
+
+// Execute imports bookmarks from a file. The whole import runs in a single
+// transaction, so on any error nothing is imported.
+func (c *ImportCommand) Execute(opts ImportOptions) error {
+	// A Firefox profile directory stands for its places.sqlite
+	filePath := parser.FirefoxPlacesPath(opts.Path)
+	if opts.Path == "-" {
+		path, err := saveStdin()
+		if err != nil {
+			return err
+		}
+		defer os.Remove(path)
+		filePath = path
+	}
+
+	root, err := c.parse(opts, filePath)
+	if err != nil {
+		return err
+	}
+	report, err := c.save(root, opts)
+	if err != nil {
+		return err
 	}
+	report.print(os.Stdout, opts.DryRun)
+	return nil
 }
 
-// Execute imports bookmarks from HTML file
-func (c *ImportCommand) Execute(filePath string) error {
-	file, err := os.Open(filePath)
+// parse reads the file to import and puts its contents into opts.Folder
+func (c *ImportCommand) parse(opts ImportOptions, filePath string) (*models.FolderNode, error) {
+	importer, err := c.importer(opts, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parser.ParseFile(importer, filePath)
 	if err != nil {
-		return fmt.Errorf("cannot open file: %w", err)
+		return nil, fmt.Errorf("failed to parse %s file: %w", importer.Name(), err)
+	}
+	if opts.Folder != "" {
+		// Saving folders merges them with existing ones, so the target
+		// folder is created only if missing
+		top := &models.FolderNode{BareURLs: root.BareURLs, HasReadLater: root.HasReadLater}
+		target := top.Subfolder(opts.Folder)
+		target.Folders, target.Bookmarks = root.Folders, root.Bookmarks
+		root = top
+	}
+	return root, nil
+}
+
+// save saves an imported tree in a single transaction, which a dry run
+// rolls back, and returns what changed
+func (c *ImportCommand) save(root *models.FolderNode, opts ImportOptions) (*importReport, error) {
+	report := &importReport{policy: opts.OnConflict}
+	err := c.repo.Transaction(func(repo repository.Repository) error {
+		run := &importRun{
+			bookmarkSvc: service.NewBookmarkService(repo),
+			folderSvc:   service.NewFolderService(repo),
+			policy:      opts.OnConflict,
+			bareURLs:    root.BareURLs,
+			readLater:   root.HasReadLater,
+			report:      report,
+		}
+		if err := run.saveFolder(root, nil); err != nil {
+			return err
+		}
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, fmt.Errorf("import failed, nothing was imported: %w", err)
 	}
-	defer file.Close()
+	return report, nil
+}
 
-	bookmarks, err := c.parser.ParseBookmarksHTML(file)
+// saveStdin copies the standard input to a temporary file, which can be
+// read twice: for format detection and for parsing. The caller removes it.
+func saveStdin() (string, error) {
+	file, err := os.CreateTemp("", "bookmarks-import-*")
 	if err != nil {
-		return fmt.Errorf("failed to parse HTML: %w", err)
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	if _, err := io.Copy(file, os.Stdin); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to read stdin: %w", err)
 	}
+	return file.Name(), nil
+}
+
+// importReport counts what an import changed, or would change in a dry run
+type importReport struct {
+	policy             models.ImportConflictPolicy
+	bookmarksCreated   int // including copies added by keep-both
+	bookmarksUpdated   int
+	bookmarksSkipped   int // existing bookmarks left alone by the conflict policy
+	bookmarksUnchanged int // existing bookmarks that are already up to date
+	foldersCreated     int
+	foldersExisting    int
+	conflicts          []importConflict
+}
 
-	imported := 0
-	updated := 0
-	for _, b := range bookmarks {
-		created, err := c.bookmarkSvc.Upsert(&b)
+// importConflict is an imported bookmark that differs from an existing one
+type importConflict struct {
+	url    string
+	action string // what the policy did, e.g. "merged"
+	detail string
+}
+
+func (r *importReport) print(w io.Writer, dryRun bool) {
+	if dryRun {
+		fmt.Fprintf(w, "Dry run, nothing was changed. The import would:\n")
+		fmt.Fprintf(w, "  bookmarks: create %d, update %d, skip %d existing and %d unchanged\n",
+			r.bookmarksCreated, r.bookmarksUpdated, r.bookmarksSkipped, r.bookmarksUnchanged)
+		fmt.Fprintf(w, "  folders:   create %d, reuse %d existing\n", r.foldersCreated, r.foldersExisting)
+	} else {
+		fmt.Fprintf(w, "Imported %d new bookmarks, updated %d existing bookmarks, skipped %d existing and %d unchanged.\n",
+			r.bookmarksCreated, r.bookmarksUpdated, r.bookmarksSkipped, r.bookmarksUnchanged)
+		if r.foldersCreated > 0 {
+			fmt.Fprintf(w, "Created %d folders.\n", r.foldersCreated)
+		}
+	}
+
+	if len(r.conflicts) > 0 {
+		fmt.Fprintf(w, "Existing bookmarks that differ (--on-conflict %s):\n", r.policy)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, c := range r.conflicts {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", c.action, c.url, c.detail)
+		}
+		tw.Flush()
+	}
+}
+
+// importRun saves an imported tree within the import transaction
+type importRun struct {
+	bookmarkSvc *service.BookmarkService
+	folderSvc   *service.FolderService
+	policy      models.ImportConflictPolicy
+	bareURLs    bool // see models.FolderNode.BareURLs
+	readLater   bool // the file carries read-later flags
+	report      *importReport
+}
+
+// saveFolder saves the bookmarks and subfolders of node into the folder
+// parentID (nil for the root). Folders with the same name and parent are
+// merged with existing ones.
+func (r *importRun) saveFolder(node *models.FolderNode, parentID *int) error {
+	for _, b := range node.Bookmarks {
+		b.FolderID = parentID
+		if err := r.saveBookmark(b); err != nil {
+			return fmt.Errorf("failed to save bookmark %q: %w", b.URL, err)
+		}
+	}
+
+	for _, sub := range node.Folders {
+		folder, err := r.folderSvc.Find(sub.Folder.Name, parentID)
 		if err != nil {
-			fmt.Printf("Warning: failed to upsert bookmark '%s': %v\n", b.Title, err)
-			continue
+			return fmt.Errorf("failed to get folder %q: %w", sub.Folder.Name, err)
 		}
-		if created {
-			imported++
+		if folder != nil {
+			r.report.foldersExisting++
 		} else {
-			updated++
+			folder = &models.Folder{
+				Name:      sub.Folder.Name,
+				ParentID:  parentID,
+				Toolbar:   sub.Folder.Toolbar,
+				CreatedAt: sub.Folder.CreatedAt,
+				UpdatedAt: sub.Folder.UpdatedAt,
+			}
+			if folder.ID, err = r.freeFolderID(sub.Folder.ID); err != nil {
+				return err
+			}
+			if err := r.folderSvc.Insert(folder); err != nil {
+				return fmt.Errorf("failed to create folder %q: %w", sub.Folder.Name, err)
+			}
+			r.report.foldersCreated++
+		}
+		folderID := folder.ID
+		if err := r.saveFolder(sub, &folderID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveBookmark creates b, or resolves the conflict with the existing
+// bookmark with its URL according to the conflict policy. A new bookmark
+// without a title is titled with its URL.
+func (r *importRun) saveBookmark(b models.Bookmark) error {
+	// With keep-both there may be several bookmarks with the URL already
+	same, err := r.bookmarkSvc.ListByURL(b.URL)
+	if err != nil {
+		return err
+	}
+	if len(same) == 0 {
+		if b.Title == "" {
+			b.Title = b.URL
 		}
+		if b.ID, err = r.freeBookmarkID(b.ID); err != nil {
+			return err
+		}
+		if _, err := r.bookmarkSvc.Upsert(&b); err != nil {
+			return err
+		}
+		r.report.bookmarksCreated++
+		return nil
 	}
+	for i := range same {
+		kept := r.overwrite(same[i], b)
+		if len(service.ChangedFields(&same[i], &kept)) == 0 {
+			r.report.bookmarksUnchanged++
+			return nil
+		}
+	}
+
+	existing := &same[0]
+	overwritten := r.overwrite(*existing, b)
+	changes := service.ChangedFields(existing, &overwritten)
 
-	fmt.Printf("Imported %d new bookmarks, updated %d existing bookmarks.\n", imported, updated)
+	switch r.policy {
+	case models.ImportSkipExisting:
+		r.skip(existing, &b, changes, "")
+		return nil
+	case models.ImportKeepNewest:
+		if !service.IsNewer(&b, existing) {
+			r.skip(existing, &b, changes, "existing is newer")
+			return nil
+		}
+	case models.ImportMergeFields:
+		merged := service.MergeBookmark(*existing, b)
+		if changes = service.ChangedFields(existing, &merged); len(changes) == 0 {
+			r.skip(existing, &b, nil, "nothing to fill in")
+			return nil
+		}
+		b = merged
+		b.UpdatedAt = time.Time{} // set to now by Replace
+	case models.ImportKeepBoth:
+		if b.Title == "" {
+			b.Title = b.URL
+		}
+		if b.ID, err = r.freeBookmarkID(b.ID); err != nil {
+			return err
+		}
+		if err := r.bookmarkSvc.Create(&b); err != nil {
+			return err
+		}
+		r.report.bookmarksCreated++
+		r.conflict("added copy", existing, &b, changes, "")
+		return nil
+	}
+	if r.policy != models.ImportMergeFields {
+		b = overwritten
+	}
+
+	// Update the bookmark compared above, there may be others with the URL
+	b.ID = existing.ID
+	if err := r.bookmarkSvc.Replace(&b); err != nil {
+		return err
+	}
+	r.report.bookmarksUpdated++
+	action := "updated"
+	if r.policy == models.ImportMergeFields {
+		action = "merged"
+	}
+	r.conflict(action, existing, &b, changes, "")
 	return nil
 }
+
+// overwrite returns b as the overwriting policies save it over cur
+func (r *importRun) overwrite(cur, b models.Bookmark) models.Bookmark {
+	if r.bareURLs && b.Title == "" {
+		b.Title, b.Description, b.FolderID = cur.Title, cur.Description, cur.FolderID
+	}
+	if !r.readLater {
+		b.ReadLater = cur.ReadLater
+	}
+	return service.OverwriteBookmark(cur, b)
+}
+
+// freeBookmarkID returns id if no bookmark (not even a trashed one) has it,
+// or 0 to get a new ID. Formats that carry IDs are restored with them.
+func (r *importRun) freeBookmarkID(id int) (int, error) {
+	if id == 0 {
+		return 0, nil
+	}
+	b, err := r.bookmarkSvc.GetByID(id)
+	if err != nil || b != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// freeFolderID is freeBookmarkID for folders
+func (r *importRun) freeFolderID(id int) (int, error) {
+	if id == 0 {
+		return 0, nil
+	}
+	f, err := r.folderSvc.GetByID(id)
+	if err != nil || f != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// skip records an existing bookmark left alone by the conflict policy
+func (r *importRun) skip(cur, b *models.Bookmark, changes []string, reason string) {
+	r.report.bookmarksSkipped++
+	r.conflict("skipped", cur, b, changes, reason)
+}
+
+// conflict adds a bookmark to the report, describing the changed fields
+func (r *importRun) conflict(action string, cur, b *models.Bookmark, changes []string, note string) {
+	parts := make([]string, 0, len(changes))
+	for _, f := range changes {
+		if f == "title" {
+			f = fmt.Sprintf("title %q -> %q", cur.Title, b.Title)
+		}
+		parts = append(parts, f)
+	}
+	detail := strings.Join(parts, ", ")
+	if note != "" {
+		detail = strings.TrimSpace(detail + " (" + note + ")")
+	}
+	r.report.conflicts = append(r.report.conflicts, importConflict{
+		url:    b.URL,
+		action: action,
+		detail: detail,
+	})
+}
@@ -0,0 +1,401 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+	"github.com/dastanaron/bookmarks/internal/repository"
+	"github.com/dastanaron/bookmarks/internal/service"
+)
+
+// newTestRepo returns a repository on an empty database in a temp directory
+func newTestRepo(t *testing.T) repository.Repository {
+	t.Helper()
+	repo, err := repository.NewSQLiteRepository(filepath.Join(t.TempDir(), "bookmarks.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+const conflictURL = "https://go.dev/"
+
+var (
+	jan2020 = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	jan2024 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jan2025 = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// seedConflict creates the bookmark that the imports in TestImportConflictPolicies
+// conflict with, in the folder Work
+func seedConflict(t *testing.T, repo repository.Repository) {
+	t.Helper()
+	folder, err := service.NewFolderService(repo).UpsertPath("Work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.Bookmarks().Create(&models.Bookmark{
+		Title:       "Old title",
+		URL:         conflictURL,
+		Description: "Old description",
+		Keyword:     "go",
+		FolderID:    &folder.ID,
+		Tags:        []string{"a"},
+		CreatedAt:   jan2024,
+		UpdatedAt:   jan2024,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// conflictTree returns an imported tree with a changed copy of the seeded
+// bookmark in Work, modified at updated, and a new bookmark in the root
+func conflictTree(updated time.Time) *models.FolderNode {
+	root := &models.FolderNode{}
+	root.Bookmarks = []models.Bookmark{{Title: "New", URL: "https://new.example/", Tags: []string{}}}
+	work := root.AddFolder("Work")
+	work.Bookmarks = []models.Bookmark{{
+		Title:       "New title",
+		URL:         conflictURL,
+		Description: "New description",
+		Tags:        []string{"b"},
+		CreatedAt:   jan2024,
+		UpdatedAt:   updated,
+	}}
+	return root
+}
+
+// reportCounts are the counts of an importReport
+type reportCounts struct {
+	created, updated, skipped, unchanged, foldersCreated, foldersExisting int
+}
+
+func countsOf(r *importReport) reportCounts {
+	return reportCounts{
+		created:         r.bookmarksCreated,
+		updated:         r.bookmarksUpdated,
+		skipped:         r.bookmarksSkipped,
+		unchanged:       r.bookmarksUnchanged,
+		foldersCreated:  r.foldersCreated,
+		foldersExisting: r.foldersExisting,
+	}
+}
+
+// bookmarkState is the part of a bookmark that the conflict policies change
+type bookmarkState struct {
+	Title, Description, Keyword string
+	Tags                        []string
+}
+
+func TestImportConflictPolicies(t *testing.T) {
+	old := bookmarkState{"Old title", "Old description", "go", []string{"a"}}
+	imported := bookmarkState{"New title", "New description", "go", []string{"b"}}
+
+	tests := []struct {
+		name    string
+		policy  models.ImportConflictPolicy
+		updated time.Time // modification time of the imported bookmark
+		want    reportCounts
+		// the bookmarks with the conflicting URL after the import, oldest first
+		wantStates []bookmarkState
+	}{
+		{
+			name:       "overwrite",
+			policy:     models.ImportOverwrite,
+			updated:    jan2020,
+			want:       reportCounts{created: 1, updated: 1, foldersExisting: 1},
+			wantStates: []bookmarkState{imported},
+		},
+		{
+			name:       "skip-existing",
+			policy:     models.ImportSkipExisting,
+			updated:    jan2025,
+			want:       reportCounts{created: 1, skipped: 1, foldersExisting: 1},
+			wantStates: []bookmarkState{old},
+		},
+		{
+			name:       "keep-newest with an older import",
+			policy:     models.ImportKeepNewest,
+			updated:    jan2020,
+			want:       reportCounts{created: 1, skipped: 1, foldersExisting: 1},
+			wantStates: []bookmarkState{old},
+		},
+		{
+			name:       "keep-newest with a newer import",
+			policy:     models.ImportKeepNewest,
+			updated:    jan2025,
+			want:       reportCounts{created: 1, updated: 1, foldersExisting: 1},
+			wantStates: []bookmarkState{imported},
+		},
+		{
+			name:    "merge-fields",
+			policy:  models.ImportMergeFields,
+			updated: jan2025,
+			want:    reportCounts{created: 1, updated: 1, foldersExisting: 1},
+			// Only the tags are added, the fields that are set are kept
+			wantStates: []bookmarkState{{"Old title", "Old description", "go", []string{"a", "b"}}},
+		},
+		{
+			name:       "keep-both",
+			policy:     models.ImportKeepBoth,
+			updated:    jan2025,
+			want:       reportCounts{created: 2, foldersExisting: 1},
+			wantStates: []bookmarkState{old, {"New title", "New description", "", []string{"b"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepo(t)
+			seedConflict(t, repo)
+			cmd := NewImportCommand(repo)
+
+			// A dry run reports the same changes without making them
+			dry, err := cmd.save(conflictTree(tt.updated), ImportOptions{OnConflict: tt.policy, DryRun: true})
+			if err != nil {
+				t.Fatalf("dry run: %v", err)
+			}
+			if got := countsOf(dry); got != tt.want {
+				t.Errorf("dry run report = %+v, want %+v", got, tt.want)
+			}
+			assertBookmarkStates(t, repo, []bookmarkState{old})
+			if all, _ := repo.Bookmarks().List(); len(all) != 1 {
+				t.Errorf("dry run left %d bookmarks, want 1", len(all))
+			}
+
+			report, err := cmd.save(conflictTree(tt.updated), ImportOptions{OnConflict: tt.policy})
+			if err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			if got := countsOf(report); got != tt.want {
+				t.Errorf("report = %+v, want %+v", got, tt.want)
+			}
+			assertBookmarkStates(t, repo, tt.wantStates)
+
+			// Importing the same tree again changes nothing
+			again, err := cmd.save(conflictTree(tt.updated), ImportOptions{OnConflict: tt.policy})
+			if err != nil {
+				t.Fatalf("second import: %v", err)
+			}
+			if again.bookmarksCreated != 0 || again.bookmarksUpdated != 0 || again.foldersCreated != 0 {
+				t.Errorf("second import report = %+v, want no changes", countsOf(again))
+			}
+		})
+	}
+}
+
+func TestImportUnchanged(t *testing.T) {
+	repo := newTestRepo(t)
+	seedConflict(t, repo)
+
+	root := &models.FolderNode{}
+	work := root.AddFolder("Work")
+	work.Bookmarks = []models.Bookmark{{
+		Title:       "Old title",
+		URL:         conflictURL,
+		Description: "Old description",
+		Tags:        []string{"a"},
+	}}
+	report, err := NewImportCommand(repo).save(root, ImportOptions{OnConflict: models.ImportOverwrite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (reportCounts{unchanged: 1, foldersExisting: 1}); countsOf(report) != want {
+		t.Errorf("report = %+v, want %+v", countsOf(report), want)
+	}
+	if len(report.conflicts) != 0 {
+		t.Errorf("conflicts = %+v, want none", report.conflicts)
+	}
+}
+
+// assertBookmarkStates checks the bookmarks with conflictURL
+func assertBookmarkStates(t *testing.T, repo repository.Repository, want []bookmarkState) {
+	t.Helper()
+	same, err := repo.Bookmarks().ListByURL(conflictURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]bookmarkState, len(same))
+	for i, b := range same {
+		got[i] = bookmarkState{b.Title, b.Description, b.Keyword, b.Tags}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bookmarks with %s = %+v, want %+v", conflictURL, got, want)
+	}
+}
+
+func TestImportUntitled(t *testing.T) {
+	tests := []struct {
+		name, file, content string
+		opts                ImportOptions
+		// where the seeded bookmark ends up and with which description
+		wantFolder, wantDescription string
+		want                        reportCounts
+	}{
+		{
+			// A URL list knows nothing but the URL, so the bookmark is kept
+			name:            "url list",
+			file:            "urls.txt",
+			content:         conflictURL + "\nhttps://new.example/\n",
+			opts:            ImportOptions{Format: "urls", Folder: "Inbox"},
+			wantFolder:      "Work",
+			wantDescription: "Old description",
+			want:            reportCounts{created: 1, unchanged: 1, foldersCreated: 1},
+		},
+		{
+			// Other formats overwrite it, even without a title
+			name: "html",
+			file: "bookmarks.html",
+			content: `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<DL><p>
+    <DT><H3>Inbox</H3>
+    <DL><p>
+        <DT><A HREF="` + conflictURL + `"></A>
+        <DT><A HREF="https://new.example/"></A>
+    </DL><p>
+</DL><p>
+`,
+			wantFolder: "Inbox",
+			want:       reportCounts{created: 1, updated: 1, foldersCreated: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepo(t)
+			seedConflict(t, repo)
+
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cmd := NewImportCommand(repo)
+			root, err := cmd.parse(tt.opts, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			report, err := cmd.save(root, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := countsOf(report); got != tt.want {
+				t.Errorf("report = %+v, want %+v", got, tt.want)
+			}
+
+			existing, err := repo.Bookmarks().GetByURL(conflictURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if existing.FolderName == nil || *existing.FolderName != tt.wantFolder || existing.Description != tt.wantDescription {
+				t.Errorf("existing bookmark = %+v, want it in %s with description %q", existing, tt.wantFolder, tt.wantDescription)
+			}
+			added, err := repo.Bookmarks().GetByURL("https://new.example/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if added.Title != added.URL || added.FolderName == nil || *added.FolderName != "Inbox" {
+				t.Errorf("new bookmark = %+v, want it titled with its URL in Inbox", added)
+			}
+		})
+	}
+}
+
+func TestImportReadLater(t *testing.T) {
+	tests := []struct {
+		name, file, content string
+		policy              models.ImportConflictPolicy
+		wantReadLater       bool
+		want                reportCounts
+	}{
+		{
+			name:    "pinboard clears the flag",
+			file:    "pinboard.json",
+			content: `[{"href":"` + conflictURL + `","description":"Go","toread":"no"}]`,
+			policy:  models.ImportOverwrite,
+			want:    reportCounts{updated: 1},
+		},
+		{
+			name:    "pinboard clears the flag of an older bookmark",
+			file:    "pinboard.json",
+			content: `[{"href":"` + conflictURL + `","description":"Go","toread":"no","time":"2025-01-01T00:00:00Z"}]`,
+			policy:  models.ImportKeepNewest,
+			want:    reportCounts{updated: 1},
+		},
+		{
+			name:          "html keeps the flag",
+			file:          "bookmarks.html",
+			content:       `<DL><p><DT><A HREF="` + conflictURL + `">Go</A></DL><p>`,
+			policy:        models.ImportOverwrite,
+			wantReadLater: true,
+			want:          reportCounts{unchanged: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepo(t)
+			err := repo.Bookmarks().Create(&models.Bookmark{
+				Title: "Go", URL: conflictURL, ReadLater: true, Tags: []string{},
+				CreatedAt: jan2024, UpdatedAt: jan2024,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cmd := NewImportCommand(repo)
+			opts := ImportOptions{OnConflict: tt.policy}
+			root, err := cmd.parse(opts, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			report, err := cmd.save(root, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := countsOf(report); got != tt.want {
+				t.Errorf("report = %+v, want %+v", got, tt.want)
+			}
+			b, err := repo.Bookmarks().GetByURL(conflictURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.ReadLater != tt.wantReadLater {
+				t.Errorf("read later = %v, want %v", b.ReadLater, tt.wantReadLater)
+			}
+		})
+	}
+}
+
+func TestImportMergesIntoOldestDuplicate(t *testing.T) {
+	repo := newTestRepo(t)
+	for _, desc := range []string{"", "Copy description"} {
+		err := repo.Bookmarks().Create(&models.Bookmark{Title: "Go", URL: conflictURL, Description: desc, Tags: []string{}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	root := &models.FolderNode{}
+	root.Bookmarks = []models.Bookmark{{Title: "Go", URL: conflictURL, Description: "Imported description"}}
+	report, err := NewImportCommand(repo).save(root, ImportOptions{OnConflict: models.ImportMergeFields})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (reportCounts{updated: 1}); countsOf(report) != want {
+		t.Errorf("report = %+v, want %+v", countsOf(report), want)
+	}
+	assertBookmarkStates(t, repo, []bookmarkState{
+		{"Go", "Imported description", "", nil},
+		{"Go", "Copy description", "", nil},
+	})
+}
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// legacyFlags are the flags used before the CLI had subcommands.
+// They are kept as deprecated aliases and translated to commands.
+type legacyFlags struct {
+	importPath    string
+	importFormat  string
+	exportPath    string
+	clearDoubles  bool
+	deleteFolder  int
+	deletePolicy  string
+	purgeTrash    bool
+	olderThan     string
+	migrateStatus bool
+}
+
+func registerLegacyFlags(fs *flag.FlagSet) *legacyFlags {
+	l := &legacyFlags{}
+	fs.StringVar(&l.importPath, "import", "", "Deprecated: use the import command")
+	fs.StringVar(&l.importFormat, "import-format", "", "Deprecated: use the import command")
+	fs.StringVar(&l.exportPath, "export", "", "Deprecated: use the export command")
+	fs.BoolVar(&l.clearDoubles, "clear-doubles", false, "Deprecated: use the dedupe command")
+	fs.IntVar(&l.deleteFolder, "delete-folder", 0, "Deprecated: use the folder rm command")
+	fs.StringVar(&l.deletePolicy, "delete-policy", "", "Deprecated: use the folder rm command")
+	fs.BoolVar(&l.purgeTrash, "purge-trash", false, "Deprecated: use the purge command")
+	fs.StringVar(&l.olderThan, "older-than", "", "Deprecated: use the purge command")
+	fs.BoolVar(&l.migrateStatus, "migrate-status", false, "Deprecated: use the migrate-status command")
+	return l
+}
+
+// commandArgs returns the command line equivalent to the deprecated flags that
+// were set, or nil if none were. Several actions at once are rejected instead
+// of silently running only one of them.
+func (l *legacyFlags) commandArgs(fs *flag.FlagSet, stderr io.Writer, program string) ([]string, error) {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var actions []string
+	var args []string
+	if set["import"] {
+		actions = append(actions, "--import")
+		args = []string{"import", l.importPath}
+		if l.importFormat != "" {
+			args = append(args, "--format", l.importFormat)
+		}
+	} else if set["import-format"] {
+		return nil, &UsageError{Msg: "--import-format requires --import"}
+	}
+	if set["export"] {
+		actions = append(actions, "--export")
+		args = []string{"export", l.exportPath}
+	}
+	if set["clear-doubles"] && l.clearDoubles {
+		actions = append(actions, "--clear-doubles")
+		args = []string{"dedupe"}
+	}
+	if set["delete-folder"] {
+		actions = append(actions, "--delete-folder")
+		args = []string{"folder", "rm", strconv.Itoa(l.deleteFolder)}
+		if l.deletePolicy != "" {
+			args = append(args, "--policy", l.deletePolicy)
+		}
+	}
+	if set["purge-trash"] && l.purgeTrash {
+		actions = append(actions, "--purge-trash")
+		args = []string{"purge"}
+		if l.olderThan != "" {
+			args = append(args, "--older-than", l.olderThan)
+		}
+	}
+	if set["migrate-status"] && l.migrateStatus {
+		actions = append(actions, "--migrate-status")
+		args = []string{"migrate-status"}
+	}
+
+	switch len(actions) {
+	case 0:
+		return nil, nil
+	case 1:
+		fmt.Fprintf(stderr, "Warning: %s is deprecated, use '%s %s' instead\n", actions[0], program, strings.Join(args, " "))
+		return args, nil
+	default:
+		return nil, &UsageError{Msg: fmt.Sprintf("%s can't be used together", strings.Join(actions, " and "))}
+	}
+}
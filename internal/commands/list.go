@@ -0,0 +1,276 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+	"github.com/dastanaron/bookmarks/internal/repository"
+	"github.com/dastanaron/bookmarks/internal/service"
+)
+
+// ListFormats are the output formats supported by ListCommand
+var ListFormats = []string{"table", "json", "tsv", "csv", "urls"}
+
+// ListOptions selects the bookmarks to print and how to print them
+type ListOptions struct {
+	Query  string   // search query, empty lists all bookmarks
+	Folder string   // slash-separated folder path, empty for all folders
+	Tags   []string // only bookmarks having all of these tags
+	// ReadLater limits the list to the bookmarks marked to read later
+	ReadLater bool
+	Format    string // one of ListFormats, empty for table
+	Limit     int    // 0 means no limit
+}
+
+// ListCommand prints bookmarks for use in scripts (list and search subcommands)
+type ListCommand struct {
+	repo        repository.Repository
+	bookmarkSvc *service.BookmarkService
+	folderSvc   *service.FolderService
+	out         io.Writer
+}
+
+// NewListCommand creates a new list command
+func NewListCommand(repo repository.Repository) *ListCommand {
+	return &ListCommand{
+		repo:        repo,
+		bookmarkSvc: service.NewBookmarkService(repo),
+		folderSvc:   service.NewFolderService(repo),
+		out:         os.Stdout,
+	}
+}
+
+// Execute prints the bookmarks selected by opts to standard output
+func (c *ListCommand) Execute(opts ListOptions) error {
+	format := opts.Format
+	if format == "" {
+		format = "table"
+	}
+	if !oneOf(format, ListFormats) {
+		return fmt.Errorf("unknown format %q (expected one of: %s)", format, strings.Join(ListFormats, ", "))
+	}
+
+	var folderID *int
+	if opts.Folder != "" {
+		folder, err := c.folderSvc.FindPath(opts.Folder)
+		if err != nil {
+			return fmt.Errorf("failed to get folders: %w", err)
+		}
+		if folder == nil {
+			return fmt.Errorf("folder %q not found", opts.Folder)
+		}
+		folderID = &folder.ID
+	}
+
+	bookmarks, err := c.find(opts, folderID)
+	if err != nil {
+		return err
+	}
+
+	paths, err := c.folderSvc.Paths()
+	if err != nil {
+		return fmt.Errorf("failed to get folders: %w", err)
+	}
+
+	switch format {
+	case "json":
+		return c.writeJSON(bookmarks, paths)
+	case "tsv":
+		return c.writeTSV(bookmarks, paths)
+	case "csv":
+		return c.writeCSV(bookmarks, paths)
+	case "urls":
+		for _, b := range bookmarks {
+			fmt.Fprintln(c.out, b.URL)
+		}
+		return nil
+	default:
+		return c.writeTable(bookmarks, paths)
+	}
+}
+
+// find returns the bookmarks matching opts, best search matches first
+func (c *ListCommand) find(opts ListOptions, folderID *int) ([]models.Bookmark, error) {
+	bookmarks, err := c.bookmarkSvc.SearchInFolder(opts.Query, folderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search bookmarks: %w", err)
+	}
+
+	tags := service.ParseTags(strings.Join(opts.Tags, ","))
+	if len(tags) > 0 {
+		tagged, err := c.bookmarkSvc.ListByTags(tags, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bookmarks by tags: %w", err)
+		}
+		ids := make(map[int]bool, len(tagged))
+		for _, b := range tagged {
+			ids[b.ID] = true
+		}
+		filtered := bookmarks[:0]
+		for _, b := range bookmarks {
+			if ids[b.ID] {
+				filtered = append(filtered, b)
+			}
+		}
+		bookmarks = filtered
+	}
+
+	if opts.ReadLater {
+		filtered := bookmarks[:0]
+		for _, b := range bookmarks {
+			if b.ReadLater {
+				filtered = append(filtered, b)
+			}
+		}
+		bookmarks = filtered
+	}
+
+	if opts.Limit > 0 && len(bookmarks) > opts.Limit {
+		bookmarks = bookmarks[:opts.Limit]
+	}
+	return bookmarks, nil
+}
+
+// listRecord is the JSON representation of a bookmark
+type listRecord struct {
+	ID            int        `json:"id"`
+	Title         string     `json:"title"`
+	URL           string     `json:"url"`
+	Description   string     `json:"description,omitempty"`
+	Keyword       string     `json:"keyword,omitempty"`
+	ReadLater     bool       `json:"read_later,omitempty"`
+	Folder        string     `json:"folder,omitempty"`
+	FolderID      *int       `json:"folder_id,omitempty"`
+	Tags          []string   `json:"tags"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+	LastVisitedAt *time.Time `json:"last_visited_at,omitempty"`
+}
+
+func (c *ListCommand) writeJSON(bookmarks []models.Bookmark, paths map[int]string) error {
+	records := make([]listRecord, 0, len(bookmarks))
+	for _, b := range bookmarks {
+		tags := b.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		records = append(records, listRecord{
+			ID:            b.ID,
+			Title:         b.Title,
+			URL:           b.URL,
+			Description:   b.Description,
+			Keyword:       b.Keyword,
+			ReadLater:     b.ReadLater,
+			Folder:        folderPath(b, paths),
+			FolderID:      b.FolderID,
+			Tags:          tags,
+			CreatedAt:     optionalTime(b.CreatedAt),
+			UpdatedAt:     optionalTime(b.UpdatedAt),
+			LastVisitedAt: optionalTime(b.LastVisitedAt),
+		})
+	}
+
+	enc := json.NewEncoder(c.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// writeTSV writes one bookmark per line without a header, for fzf, dmenu and cut
+func (c *ListCommand) writeTSV(bookmarks []models.Bookmark, paths map[int]string) error {
+	clean := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+	for _, b := range bookmarks {
+		fields := listFields(b, paths)
+		for i := range fields {
+			fields[i] = clean.Replace(fields[i])
+		}
+		if _, err := fmt.Fprintln(c.out, strings.Join(fields, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ListCommand) writeCSV(bookmarks []models.Bookmark, paths map[int]string) error {
+	w := csv.NewWriter(c.out)
+	if err := w.Write([]string{"id", "title", "url", "folder", "tags", "description", "read_later"}); err != nil {
+		return err
+	}
+	for _, b := range bookmarks {
+		if err := w.Write(listFields(b, paths)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func (c *ListCommand) writeTable(bookmarks []models.Bookmark, paths map[int]string) error {
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tURL\tFOLDER\tTAGS\tREAD LATER")
+	for _, b := range bookmarks {
+		later := ""
+		if b.ReadLater {
+			later = "yes"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			b.ID, truncate(b.Title, 50), truncate(b.URL, 70), folderPath(b, paths), strings.Join(b.Tags, ","), later)
+	}
+	return w.Flush()
+}
+
+// listFields returns the TSV/CSV columns of a bookmark:
+// id, title, url, folder, tags, description, read_later
+func listFields(b models.Bookmark, paths map[int]string) []string {
+	return []string{
+		strconv.Itoa(b.ID),
+		b.Title,
+		b.URL,
+		folderPath(b, paths),
+		strings.Join(b.Tags, ","),
+		b.Description,
+		strconv.FormatBool(b.ReadLater),
+	}
+}
+
+// folderPath returns the folder path of a bookmark, empty for the root
+func folderPath(b models.Bookmark, paths map[int]string) string {
+	if b.FolderID == nil {
+		return ""
+	}
+	return paths[*b.FolderID]
+}
+
+// optionalTime returns nil for an unknown (zero) time
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// truncate shortens s to at most n runes, marking the cut with "…"
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// oneOf reports whether s is one of values
+func oneOf(s string, values []string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
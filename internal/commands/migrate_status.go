@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dastanaron/bookmarks/internal/repository"
+)
+
+// MigrateStatusCommand reports the schema version of a database
+type MigrateStatusCommand struct {
+	dbPath string
+}
+
+// NewMigrateStatusCommand creates a new migrate status command.
+// It takes a path instead of a repository because opening a repository
+// applies pending migrations.
+func NewMigrateStatusCommand(dbPath string) *MigrateStatusCommand {
+	return &MigrateStatusCommand{dbPath: dbPath}
+}
+
+// Execute prints applied and pending migrations without changing the database
+func (c *MigrateStatusCommand) Execute() error {
+	if _, err := os.Stat(c.dbPath); os.IsNotExist(err) {
+		fmt.Printf("Database %s does not exist yet; it will be created at the latest schema version.\n", c.dbPath)
+		return nil
+	}
+
+	status, err := repository.ReadSchemaStatus(c.dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to read schema status: %w", err)
+	}
+
+	fmt.Printf("Database: %s\n", c.dbPath)
+	fmt.Printf("Schema version: %d (this binary supports up to %d)\n", status.CurrentVersion, status.LatestVersion)
+
+	pending := 0
+	for _, m := range status.Migrations {
+		switch {
+		case m.Unknown:
+			fmt.Printf("  [?] %3d  %s (applied %s by a newer binary)\n", m.Version, m.Name, m.AppliedAt)
+		case m.Applied:
+			fmt.Printf("  [x] %3d  %s (applied %s)\n", m.Version, m.Name, m.AppliedAt)
+		default:
+			fmt.Printf("  [ ] %3d  %s\n", m.Version, m.Name)
+			pending++
+		}
+	}
+
+	if status.CurrentVersion > status.LatestVersion {
+		fmt.Println("This database was migrated by a newer binary and cannot be opened by this one.")
+	} else if pending > 0 {
+		fmt.Printf("%d pending migration(s) will be applied the next time the database is opened.\n", pending)
+	} else {
+		fmt.Println("Schema is up to date.")
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+	"github.com/dastanaron/bookmarks/internal/repository"
+	"github.com/dastanaron/bookmarks/internal/service"
+)
+
+// MoveCommand moves bookmarks to another folder
+type MoveCommand struct {
+	repo repository.Repository
+}
+
+// NewMoveCommand creates a new move command
+func NewMoveCommand(repo repository.Repository) *MoveCommand {
+	return &MoveCommand{repo: repo}
+}
+
+// Execute moves the bookmarks given by ID or URL to the folder at folderPath,
+// creating it if needed. An empty path or "/" moves them to the root. It runs
+// in a single transaction, so nothing is moved if one is missing or fails.
+func (c *MoveCommand) Execute(refs []string, folderPath string) error {
+	var bookmarks []models.Bookmark
+	var folder *models.Folder
+	err := c.repo.Transaction(func(repo repository.Repository) error {
+		bookmarkSvc := service.NewBookmarkService(repo)
+		var err error
+		if bookmarks, err = resolveBookmarks(bookmarkSvc, refs); err != nil {
+			return err
+		}
+
+		if folder, err = service.NewFolderService(repo).UpsertPath(folderPath); err != nil {
+			return fmt.Errorf("failed to create folder: %w", err)
+		}
+		var folderID *int
+		if folder != nil {
+			folderID = &folder.ID
+		}
+
+		for i := range bookmarks {
+			b := &bookmarks[i]
+			b.FolderID = folderID
+			b.Tags = nil // leave tags unchanged
+			if err := bookmarkSvc.Update(b); err != nil {
+				return fmt.Errorf("failed to move bookmark %d: %w", b.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	target := "/"
+	if folder != nil {
+		target = folderPath
+	}
+	fmt.Printf("Moved %d bookmarks to %s\n", len(bookmarks), target)
+	return nil
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/repository"
+	"github.com/dastanaron/bookmarks/internal/service"
+)
+
+// PurgeCommand permanently deletes items from the trash
+type PurgeCommand struct {
+	repo     repository.Repository
+	trashSvc *service.TrashService
+}
+
+// NewPurgeCommand creates a new purge command
+func NewPurgeCommand(repo repository.Repository) *PurgeCommand {
+	return &PurgeCommand{
+		repo:     repo,
+		trashSvc: service.NewTrashService(repo),
+	}
+}
+
+// Execute deletes items that have been in the trash longer than olderThan.
+// An olderThan of 0 empties the whole trash.
+func (c *PurgeCommand) Execute(olderThan time.Duration) error {
+	bookmarks, folders, err := c.trashSvc.Empty(olderThan)
+	if err != nil {
+		return fmt.Errorf("failed to empty trash: %w", err)
+	}
+
+	fmt.Printf("Permanently deleted %d bookmarks and %d folders from the trash\n", bookmarks, folders)
+	return nil
+}
+
+// ParseAge parses an age such as "30d", "12h" or "0".
+// Besides time.ParseDuration units it accepts "d" for days.
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+	"github.com/dastanaron/bookmarks/internal/repository"
+	"github.com/dastanaron/bookmarks/internal/service"
+)
+
+// RemoveCommand moves bookmarks to the trash
+type RemoveCommand struct {
+	repo repository.Repository
+}
+
+// NewRemoveCommand creates a new remove command
+func NewRemoveCommand(repo repository.Repository) *RemoveCommand {
+	return &RemoveCommand{repo: repo}
+}
+
+// Execute moves the bookmarks given by ID or URL to the trash. It runs in a
+// single transaction, so nothing is removed if one is missing or fails.
+func (c *RemoveCommand) Execute(refs []string) error {
+	var bookmarks []models.Bookmark
+	err := c.repo.Transaction(func(repo repository.Repository) error {
+		bookmarkSvc := service.NewBookmarkService(repo)
+		var err error
+		if bookmarks, err = resolveBookmarks(bookmarkSvc, refs); err != nil {
+			return err
+		}
+		for _, b := range bookmarks {
+			if err := bookmarkSvc.Delete(b.ID); err != nil {
+				return fmt.Errorf("failed to delete bookmark %d: %w", b.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Moved %d bookmarks to the trash\n", len(bookmarks))
+	return nil
+}
+
+// resolveBookmarks looks up bookmarks by ID or URL, skipping trashed ones
+func resolveBookmarks(bookmarkSvc *service.BookmarkService, refs []string) ([]models.Bookmark, error) {
+	var bookmarks []models.Bookmark
+	for _, ref := range refs {
+		var b *models.Bookmark
+		var err error
+		if id, convErr := strconv.Atoi(ref); convErr == nil {
+			b, err = bookmarkSvc.GetByID(id)
+		} else {
+			b, err = bookmarkSvc.GetByURL(ref)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bookmark %s: %w", ref, err)
+		}
+		if b == nil || !b.DeletedAt.IsZero() {
+			return nil, fmt.Errorf("bookmark %s not found", ref)
+		}
+		bookmarks = append(bookmarks, *b)
+	}
+	return bookmarks, nil
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/dastanaron/bookmarks/internal/repository"
+	"github.com/dastanaron/bookmarks/internal/service"
+	"github.com/dastanaron/bookmarks/internal/site"
+)
+
+// SiteOptions selects where to generate a static site and what it contains
+type SiteOptions struct {
+	Dir    string // output directory
+	Folder string // ID or slash-separated path of the folder to publish, empty for all
+	Title  string // site title; defaults to the folder name or "Bookmarks"
+}
+
+// SiteCommand generates a static HTML site from the bookmarks
+type SiteCommand struct {
+	repo      repository.Repository
+	folderSvc *service.FolderService
+}
+
+// NewSiteCommand creates a new site command
+func NewSiteCommand(repo repository.Repository) *SiteCommand {
+	return &SiteCommand{
+		repo:      repo,
+		folderSvc: service.NewFolderService(repo),
+	}
+}
+
+// Execute writes the site for all bookmarks, or for the subtree of
+// opts.Folder, into opts.Dir
+func (c *SiteCommand) Execute(opts SiteOptions) error {
+	root, err := c.folderSvc.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to get bookmarks: %w", err)
+	}
+
+	title := opts.Title
+	if opts.Folder != "" {
+		if root, err = folderSubtree(c.folderSvc, root, opts.Folder); err != nil {
+			return err
+		}
+		if title == "" {
+			title = root.Folder.Name
+		}
+	}
+
+	if err := site.Generate(opts.Dir, root, site.Options{Title: title}); err != nil {
+		return fmt.Errorf("failed to write site to %s: %w", opts.Dir, err)
+	}
+
+	folders, bookmarks := root.Count()
+	fmt.Printf("Generated a site with %d bookmarks in %d folders in %s (open %s/index.html)\n",
+		bookmarks, folders, opts.Dir, opts.Dir)
+	return nil
+}
@@ -0,0 +1,447 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+	"github.com/dastanaron/bookmarks/internal/parser"
+	"github.com/dastanaron/bookmarks/internal/service"
+)
+
+// This file adapts the commands of this package to the Command interface:
+// each adapter parses its arguments, opens the repository and calls Execute.
+
+type tuiCLI struct{}
+
+func (c *tuiCLI) Name() string     { return "tui" }
+func (c *tuiCLI) Synopsis() string { return "" }
+func (c *tuiCLI) Summary() string  { return "Start the terminal UI (default)" }
+
+func (c *tuiCLI) Run(env *Env, args []string) error {
+	fs := newFlagSet(env, c)
+	positional, err := parseArgs(c, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageErrorf(c, "unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	repo, err := env.Repository()
+	if err != nil {
+		return err
+	}
+	return NewTUICommand(repo).Execute()
+}
+
+type addCLI struct{}
+
+func (c *addCLI) Name() string { return "add" }
+func (c *addCLI) Synopsis() string {
+	return "<url> [--title T] [--desc D] [--folder path/to/folder] [--tag T ...] [--read-later[=false]]"
+}
+func (c *addCLI) Summary() string {
+	return "Add a bookmark, or update the one with the same URL, and print its ID"
+}
+
+func (c *addCLI) Run(env *Env, args []string) error {
+	fs := newFlagSet(env, c)
+	title := fs.String("title", "", "Bookmark title (default: the URL)")
+	desc := fs.String("desc", "", "Bookmark description")
+	folder := fs.String("folder", "", "Folder path such as Work/Projects, missing folders are created")
+	var tags stringList
+	fs.Var(&tags, "tag", "Tag to add, may be repeated or comma-separated")
+	readLater := fs.Bool("read-later", false, "Mark to read later; --read-later=false unmarks")
+
+	positional, err := parseArgs(c, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf(c, "expected exactly one URL, got %d arguments", len(positional))
+	}
+
+	repo, err := env.Repository()
+	if err != nil {
+		return err
+	}
+	return NewAddCommand(repo).Execute(AddOptions{
+		URL:         positional[0],
+		Title:       *title,
+		Description: *desc,
+		Folder:      *folder,
+		Tags:        service.ParseTags(tags.String()),
+		ReadLater:   flagIfSet(fs, "read-later", *readLater),
+	})
+}
+
+// listCLI is both the list and the search command
+type listCLI struct {
+	name string
+}
+
+func (c *listCLI) Name() string { return c.name }
+func (c *listCLI) Synopsis() string {
+	if c.name == "search" {
+		return "<query> [--folder path] [--tag T ...] [--read-later] [--format F] [--limit N]"
+	}
+	return "[--folder path] [--tag T ...] [--read-later] [--format F] [--limit N]"
+}
+func (c *listCLI) Summary() string {
+	if c.name == "search" {
+		return "Print bookmarks matching a query, best matches first"
+	}
+	return "Print bookmarks"
+}
+
+func (c *listCLI) Run(env *Env, args []string) error {
+	fs := newFlagSet(env, c)
+	folder := fs.String("folder", "", "Only bookmarks directly in this folder (path such as Work/Projects)")
+	format := fs.String("format", "table", "Output format: "+strings.Join(ListFormats, ", "))
+	limit := fs.Int("limit", 0, "Maximum number of bookmarks to print (0 for all)")
+	var tags stringList
+	fs.Var(&tags, "tag", "Only bookmarks with this tag, may be repeated or comma-separated")
+	readLater := fs.Bool("read-later", false, "Only bookmarks marked to read later")
+
+	positional, err := parseArgs(c, fs, args)
+	if err != nil {
+		return err
+	}
+	query := strings.Join(positional, " ")
+	if c.name == "search" && strings.TrimSpace(query) == "" {
+		return usageErrorf(c, "search query is required")
+	}
+	if c.name == "list" && len(positional) > 0 {
+		return usageErrorf(c, "unexpected arguments: %s", query)
+	}
+	if !oneOf(*format, ListFormats) {
+		return usageErrorf(c, "unknown format %q (expected one of: %s)", *format, strings.Join(ListFormats, ", "))
+	}
+
+	repo, err := env.Repository()
+	if err != nil {
+		return err
+	}
+	if c.name == "search" && !repo.FullTextSearch() {
+		fmt.Fprintln(env.Stderr, "warning: built without the sqlite_fts5 tag, so search scans every bookmark "+
+			"and results are not ranked; rebuild with `make build` for fast ranked search")
+	}
+	return NewListCommand(repo).Execute(ListOptions{
+		Query:     query,
+		Folder:    *folder,
+		Tags:      service.ParseTags(tags.String()),
+		ReadLater: *readLater,
+		Format:    *format,
+		Limit:     *limit,
+	})
+}
+
+type removeCLI struct{}
+
+func (c *removeCLI) Name() string     { return "rm" }
+func (c *removeCLI) Synopsis() string { return "<id|url>..." }
+func (c *removeCLI) Summary() string  { return "Move bookmarks to the trash" }
+
+func (c *removeCLI) Run(env *Env, args []string) error {
+	fs := newFlagSet(env, c)
+	positional, err := parseArgs(c, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usageErrorf(c, "expected at least one bookmark ID or URL")
+	}
+
+	repo, err := env.Repository()
+	if err != nil {
+		return err
+	}
+	return NewRemoveCommand(repo).Execute(positional)
+}
+
+type moveCLI struct{}
+
+func (c *moveCLI) Name() string     { return "mv" }
+func (c *moveCLI) Synopsis() string { return "<id|url>... --folder path/to/folder" }
+func (c *moveCLI) Summary() string  { return "Move bookmarks to another folder" }
+
+func (c *moveCLI) Run(env *Env, args []string) error {
+	fs := newFlagSet(env, c)
+	folder := fs.String("folder", "", `Target folder path, created if missing; "/" for the root`)
+	positional, err := parseArgs(c, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usageErrorf(c, "expected at least one bookmark ID or URL")
+	}
+	if *folder == "" {
+		return usageErrorf(c, "--folder is required")
+	}
+
+	repo, err := env.Repository()
+	if err != nil {
+		return err
+	}
+	return NewMoveCommand(repo).Execute(positional, *folder)
+}
+
+type folderCLI struct{}
+
+func (c *folderCLI) Name() string { return "folder" }
+func (c *folderCLI) Synopsis() string {
+	return "list | add <path> | rm <id|path> [--policy move|cascade]"
+}
+func (c *folderCLI) Summary() string { return "List, create or delete folders" }
+
+func (c *folderCLI) Run(env *Env, args []string) error {
+	fs := newFlagSet(env, c)
+	policy := fs.String("policy", "", "With rm: move (contents go to the parent folder) or cascade (trash contents too); required for non-empty folders")
+	positional, err := parseArgs(c, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usageErrorf(c, "expected list, add or rm")
+	}
+
+	action, positional := positional[0], positional[1:]
+	switch action {
+	case "list":
+		if len(positional) > 0 {
+			return usageErrorf(c, "unexpected arguments: %s", strings.Join(positional, " "))
+		}
+	case "add", "rm":
+		if len(positional) != 1 {
+			return usageErrorf(c, "folder %s expects exactly one folder", action)
+		}
+	default:
+		return usageErrorf(c, "unknown folder action %q", action)
+	}
+	if *policy != "" && action != "rm" {
+		return usageErrorf(c, "--policy is only used by folder rm")
+	}
+
+	repo, err := env.Repository()
+	if err != nil {
+		return err
+	}
+	folderCmd := NewFolderCommand(repo)
+	switch action {
+	case "add":
+		return folderCmd.Add(positional[0])
+	case "rm":
+		return folderCmd.Delete(positional[0], *policy)
+	default:
+		return folderCmd.List()
+	}
+}
+
+type importCLI struct{}
+
+func (c *importCLI) Name() string { return "import" }
+func (c *importCLI) Synopsis() string {
+	return "<file|firefox-profile|-> [--format F] [--folder path] [--columns MAP] [--tag-mode M] [--on-conflict P] [--dry-run]"
+}
+func (c *importCLI) Summary() string {
+	return "Import bookmarks from a Netscape HTML, Chromium Bookmarks, Firefox places.sqlite, buku, Pinboard, Delicious, OPML, JSON, CSV or URL list file"
+}
+
+func (c *importCLI) Run(env *Env, args []string) error {
+	fs := newFlagSet(env, c)
+	format := fs.String("format", "auto", "File format: "+strings.Join(ImportFormats(), ", "))
+	onConflict := fs.String("on-conflict", "overwrite", "What to do with bookmarks whose URL exists: "+
+		strings.Join(models.ImportConflictPolicyNames, ", "))
+	dryRun := fs.Bool("dry-run", false, "Only show what would be created, updated or skipped")
+	folder := fs.String("folder", "", "Import into this folder (created if missing) instead of the root")
+	columns := fs.String("columns", "", "CSV column mapping such as url=1,title=2,folder=3 (default: from the header row)")
+	tagMode := fs.String("tag-mode", "tags", "Pinboard/Delicious/buku tags: tags keeps them as tags, "+
+		"folder puts each bookmark into a folder named after its first tag")
+	positional, err := parseArgs(c, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf(c, "expected exactly one file (- for stdin)")
+	}
+	if !oneOf(*format, ImportFormats()) {
+		return usageErrorf(c, "unknown format %q (expected one of: %s)", *format, strings.Join(ImportFormats(), ", "))
+	}
+	policy, err := service.ParseImportConflictPolicy(*onConflict)
+	if err != nil {
+		return usageErrorf(c, "%v", err)
+	}
+	if !oneOf(*tagMode, []string{"tags", "folder"}) {
+		return usageErrorf(c, "unknown tag mode %q (expected tags or folder)", *tagMode)
+	}
+	var csvColumns parser.CSVColumns
+	if *columns != "" {
+		if csvColumns, err = parser.ParseCSVColumns(*columns); err != nil {
+			return usageErrorf(c, "--columns: %v", err)
+		}
+	}
+
+	repo, err := env.Repository()
+	if err != nil {
+		return err
+	}
+	return NewImportCommand(repo).Execute(ImportOptions{
+		Path:       positional[0],
+		Format:     *format,
+		DryRun:     *dryRun,
+		OnConflict: policy,
+		Columns:    csvColumns,
+		TagFolders: *tagMode == "folder",
+		Folder:     *folder,
+	})
+}
+
+type exportCLI struct{}
+
+func (c *exportCLI) Name() string { return "export" }
+func (c *exportCLI) Synopsis() string {
+	return "<file|dir> [--format F] [--folder id|path] [--query Q] [--per-folder] [--front-matter]"
+}
+func (c *exportCLI) Summary() string {
+	return "Export bookmarks to a Netscape HTML, native JSON, Markdown, CSV, OPML, Chromium or Firefox JSON file"
+}
+
+func (c *exportCLI) Run(env *Env, args []string) error {
+	fs := newFlagSet(env, c)
+	format := fs.String("format", "auto", "File format: "+strings.Join(ExportFormats(), ", ")+
+		" (auto picks it from the file extension, default html)")
+	perFolder := fs.Bool("per-folder", false, "Write one file per folder into the directory <dir> (markdown)")
+	frontMatter := fs.Bool("front-matter", false, "Start each file with YAML front matter (markdown)")
+	folder := fs.String("folder", "", "Export only this folder (ID or path) and its subfolders, as the top level of the file")
+	query := fs.String("query", "", "Export only the bookmarks matching this search query")
+	positional, err := parseArgs(c, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf(c, "expected exactly one file")
+	}
+	if !oneOf(*format, ExportFormats()) {
+		return usageErrorf(c, "unknown format %q (expected one of: %s)", *format, strings.Join(ExportFormats(), ", "))
+	}
+
+	repo, err := env.Repository()
+	if err != nil {
+		return err
+	}
+	return NewExportCommand(repo).Execute(ExportOptions{
+		Path:        positional[0],
+		Format:      *format,
+		PerFolder:   *perFolder,
+		FrontMatter: *frontMatter,
+		Folder:      *folder,
+		Query:       *query,
+	})
+}
+
+type siteCLI struct{}
+
+func (c *siteCLI) Name() string     { return "site" }
+func (c *siteCLI) Synopsis() string { return "<dir> [--folder id|path] [--title T]" }
+func (c *siteCLI) Summary() string {
+	return "Generate a static HTML site with folder pages and search"
+}
+
+func (c *siteCLI) Run(env *Env, args []string) error {
+	fs := newFlagSet(env, c)
+	folder := fs.String("folder", "", "Publish only this folder (ID or path) and its subfolders")
+	title := fs.String("title", "", "Site title (default: the folder name, or Bookmarks)")
+	positional, err := parseArgs(c, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf(c, "expected exactly one directory")
+	}
+
+	repo, err := env.Repository()
+	if err != nil {
+		return err
+	}
+	return NewSiteCommand(repo).Execute(SiteOptions{
+		Dir:    positional[0],
+		Folder: *folder,
+		Title:  *title,
+	})
+}
+
+type dedupeCLI struct{}
+
+func (c *dedupeCLI) Name() string     { return "dedupe" }
+func (c *dedupeCLI) Synopsis() string { return "" }
+func (c *dedupeCLI) Summary() string {
+	return "Remove duplicate bookmarks (same URL), merging their tags"
+}
+
+func (c *dedupeCLI) Run(env *Env, args []string) error {
+	fs := newFlagSet(env, c)
+	positional, err := parseArgs(c, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageErrorf(c, "unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	repo, err := env.Repository()
+	if err != nil {
+		return err
+	}
+	return NewClearDoublesCommand(repo).Execute()
+}
+
+type purgeCLI struct{}
+
+func (c *purgeCLI) Name() string     { return "purge" }
+func (c *purgeCLI) Synopsis() string { return "[--older-than AGE]" }
+func (c *purgeCLI) Summary() string  { return "Permanently delete items from the trash" }
+
+func (c *purgeCLI) Run(env *Env, args []string) error {
+	fs := newFlagSet(env, c)
+	olderThan := fs.String("older-than", "30d", "Only delete items trashed longer ago than this (e.g. 30d, 12h; 0 for all)")
+	positional, err := parseArgs(c, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageErrorf(c, "unexpected arguments: %s", strings.Join(positional, " "))
+	}
+	age, err := ParseAge(*olderThan)
+	if err != nil {
+		return usageErrorf(c, "%v", err)
+	}
+
+	repo, err := env.Repository()
+	if err != nil {
+		return err
+	}
+	return NewPurgeCommand(repo).Execute(age)
+}
+
+type migrateStatusCLI struct{}
+
+func (c *migrateStatusCLI) Name() string     { return "migrate-status" }
+func (c *migrateStatusCLI) Synopsis() string { return "" }
+func (c *migrateStatusCLI) Summary() string {
+	return "Show applied and pending schema migrations without changing the database"
+}
+
+func (c *migrateStatusCLI) Run(env *Env, args []string) error {
+	fs := newFlagSet(env, c)
+	positional, err := parseArgs(c, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageErrorf(c, "unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	// Doesn't open the repository, opening it applies pending migrations
+	return NewMigrateStatusCommand(env.Config.DBPath).Execute()
+}
@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"github.com/dastanaron/bookmarks/internal/repository"
+	"github.com/dastanaron/bookmarks/internal/service"
+	"github.com/dastanaron/bookmarks/internal/ui"
+)
+
+// TUICommand starts the terminal user interface
+type TUICommand struct {
+	repo repository.Repository
+}
+
+// NewTUICommand creates a new TUI command
+func NewTUICommand(repo repository.Repository) *TUICommand {
+	return &TUICommand{repo: repo}
+}
+
+// Execute runs the TUI until the user quits
+func (c *TUICommand) Execute() error {
+	bookmarkSvc := service.NewBookmarkService(c.repo)
+	folderSvc := service.NewFolderService(c.repo)
+	trashSvc := service.NewTrashService(c.repo)
+	app := ui.NewApp(bookmarkSvc, folderSvc, trashSvc)
+	return app.Run()
+}
@@ -0,0 +1,222 @@
+package exporter
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf16"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// chromeNode is a bookmark or folder in a Chromium "Bookmarks" file. Fields
+// are in the alphabetical order Chromium writes them in.
+type chromeNode struct {
+	Children     *[]chromeNode `json:"children,omitempty"` // only set for folders
+	DateAdded    string        `json:"date_added"`
+	DateLastUsed string        `json:"date_last_used"`
+	DateModified string        `json:"date_modified,omitempty"`
+	GUID         string        `json:"guid"`
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Type         string        `json:"type"` // "url" or "folder"
+	URL          string        `json:"url,omitempty"`
+}
+
+// chromeFile is the top level of a Chromium "Bookmarks" file
+type chromeFile struct {
+	Checksum string `json:"checksum"`
+	Roots    struct {
+		BookmarkBar chromeNode `json:"bookmark_bar"`
+		Other       chromeNode `json:"other"`
+		Synced      chromeNode `json:"synced"`
+	} `json:"roots"`
+	Version int `json:"version"`
+}
+
+// GUIDs of the Chromium root folders, the same in every profile
+const (
+	chromeBookmarkBarGUID = "0bc5d13f-2cba-5d74-951f-3f233fe6c908"
+	chromeOtherGUID       = "82b081ec-3dd3-529c-8475-ab6c344590dd"
+	chromeSyncedGUID      = "4cf2e351-0e85-532b-bb37-df045d8f8d0f"
+)
+
+// ChromeExporter writes the "Bookmarks" file of a Chromium-based browser
+// profile. Chromium has no tags, descriptions or keywords for bookmarks, so
+// only titles, URLs, folders and dates are written.
+type ChromeExporter struct{}
+
+// NewChromeExporter creates a new Chromium bookmarks exporter
+func NewChromeExporter() *ChromeExporter {
+	return &ChromeExporter{}
+}
+
+// Name returns the format name
+func (e *ChromeExporter) Name() string {
+	return "chrome-json"
+}
+
+// Extension returns the file name extension. The file itself has none
+// ("Bookmarks"), and .json files get the native format, so the format is
+// only used when asked for by name.
+func (e *ChromeExporter) Extension() string {
+	return ".json"
+}
+
+// Export writes root as a Chromium "Bookmarks" file. The contents of the
+// toolbar folder go to the bookmarks bar and everything else to "Other
+// bookmarks", the reverse of ChromeImporter. Every node gets a new ID and
+// GUID, and the file gets the checksum Chromium verifies on load.
+func (e *ChromeExporter) Export(w io.Writer, root *models.FolderNode) error {
+	enc := &chromeEncoder{now: time.Now(), checksum: md5.New()}
+	toolbar, rest := splitToolbar(root)
+	if toolbar == nil {
+		toolbar = &models.FolderNode{}
+	}
+
+	var file chromeFile
+	var err error
+	// Roots are numbered and hashed first, in this order
+	if file.Roots.BookmarkBar, err = enc.root("Bookmarks bar", chromeBookmarkBarGUID, toolbar); err != nil {
+		return err
+	}
+	if file.Roots.Other, err = enc.root("Other bookmarks", chromeOtherGUID, rest); err != nil {
+		return err
+	}
+	if file.Roots.Synced, err = enc.root("Mobile bookmarks", chromeSyncedGUID, &models.FolderNode{}); err != nil {
+		return err
+	}
+	file.Checksum = hex.EncodeToString(enc.checksum.Sum(nil))
+	file.Version = 1
+
+	data, err := json.MarshalIndent(file, "", "   ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// chromeEncoder numbers the nodes of a Chromium bookmarks file and computes
+// its checksum: the MD5 of the ID, UTF-16 name and type (and URL) of every
+// node, in the order they appear in the file
+type chromeEncoder struct {
+	now      time.Time
+	lastID   int
+	checksum hash.Hash
+}
+
+// root returns a root folder with the contents of node
+func (e *chromeEncoder) root(name, guid string, node *models.FolderNode) (chromeNode, error) {
+	folder := models.Folder{Name: name, CreatedAt: node.Folder.CreatedAt, UpdatedAt: node.Folder.UpdatedAt}
+	return e.folder(folder, guid, node)
+}
+
+// folder returns a folder node with the contents of node
+func (e *chromeEncoder) folder(f models.Folder, guid string, node *models.FolderNode) (chromeNode, error) {
+	if guid == "" {
+		var err error
+		if guid, err = newGUID(); err != nil {
+			return chromeNode{}, err
+		}
+	}
+	n := chromeNode{
+		DateAdded:    e.time(f.CreatedAt),
+		DateLastUsed: "0",
+		DateModified: e.time(f.UpdatedAt),
+		GUID:         guid,
+		ID:           e.nextID(),
+		Name:         f.Name,
+		Type:         "folder",
+	}
+	e.sum(n.ID, n.Name, n.Type)
+
+	children := []chromeNode{}
+	for _, b := range node.Bookmarks {
+		child, err := e.bookmark(b)
+		if err != nil {
+			return chromeNode{}, err
+		}
+		children = append(children, child)
+	}
+	for _, sub := range node.Folders {
+		child, err := e.folder(sub.Folder, "", sub)
+		if err != nil {
+			return chromeNode{}, err
+		}
+		children = append(children, child)
+	}
+	n.Children = &children
+	return n, nil
+}
+
+// bookmark returns the url node of a bookmark
+func (e *chromeEncoder) bookmark(b models.Bookmark) (chromeNode, error) {
+	guid, err := newGUID()
+	if err != nil {
+		return chromeNode{}, err
+	}
+	n := chromeNode{
+		DateAdded:    e.time(b.CreatedAt),
+		DateLastUsed: "0",
+		GUID:         guid,
+		ID:           e.nextID(),
+		Name:         b.Title,
+		Type:         "url",
+		URL:          b.URL,
+	}
+	if !b.LastVisitedAt.IsZero() {
+		n.DateLastUsed = models.WebKitTime(b.LastVisitedAt)
+	}
+	e.sum(n.ID, n.Name, n.Type, n.URL)
+	return n, nil
+}
+
+// nextID returns the next node ID. IDs start at 1 and are unique in the file.
+func (e *chromeEncoder) nextID() string {
+	e.lastID++
+	return strconv.Itoa(e.lastID)
+}
+
+// sum adds the fields of a node to the checksum. Chromium hashes the name
+// as UTF-16 (little-endian) and everything else as UTF-8.
+func (e *chromeEncoder) sum(id, name, typ string, url ...string) {
+	io.WriteString(e.checksum, id)
+	units := utf16.Encode([]rune(name))
+	buf := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		buf = append(buf, byte(u), byte(u>>8))
+	}
+	e.checksum.Write(buf)
+	io.WriteString(e.checksum, typ)
+	for _, u := range url {
+		io.WriteString(e.checksum, u)
+	}
+}
+
+// time formats a timestamp for a date_added or date_modified field.
+// Unknown times are set to the time of the export, as Chromium would.
+func (e *chromeEncoder) time(t time.Time) string {
+	if t.IsZero() {
+		t = e.now
+	}
+	return models.WebKitTime(t)
+}
+
+// newGUID returns a random (version 4) UUID in the lowercase form Chromium uses
+func newGUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate GUID: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
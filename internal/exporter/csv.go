@@ -0,0 +1,93 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// csvHeader lists the columns written by CSVExporter. The first six are
+// the same as in "list --format csv", and the CSV importer reads all of them,
+// the IDs only from a header exactly like this one.
+var csvHeader = []string{
+	"id", "title", "url", "folder", "tags", "description", "keyword",
+	"created_at", "updated_at", "last_visited_at", "read_later",
+}
+
+// CSVExporter writes one bookmark per row, with a header row, for
+// spreadsheets and link inventories
+type CSVExporter struct{}
+
+// NewCSVExporter creates a new CSV exporter
+func NewCSVExporter() *CSVExporter {
+	return &CSVExporter{}
+}
+
+// Name returns the format name
+func (e *CSVExporter) Name() string {
+	return "csv"
+}
+
+// Extension returns the file name extension
+func (e *CSVExporter) Extension() string {
+	return ".csv"
+}
+
+// Export writes the bookmarks below root as CSV. Folders are written as
+// slash-separated paths such as "Work/Infra/K8s", tags comma-separated and
+// timestamps in RFC 3339 (UTC), empty if unknown.
+func (e *CSVExporter) Export(w io.Writer, root *models.FolderNode) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	if err := e.writeFolder(cw, root, ""); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeFolder writes the bookmarks of a folder, then those of its subfolders
+func (e *CSVExporter) writeFolder(cw *csv.Writer, node *models.FolderNode, path string) error {
+	for _, b := range node.Bookmarks {
+		err := cw.Write([]string{
+			strconv.Itoa(b.ID),
+			b.Title,
+			b.URL,
+			path,
+			strings.Join(b.Tags, ","),
+			b.Description,
+			b.Keyword,
+			csvTime(b.CreatedAt),
+			csvTime(b.UpdatedAt),
+			csvTime(b.LastVisitedAt),
+			strconv.FormatBool(b.ReadLater),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, child := range node.Folders {
+		childPath := child.Folder.Name
+		if path != "" {
+			childPath = path + "/" + childPath
+		}
+		if err := e.writeFolder(cw, child, childPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// csvTime formats a timestamp for CSV, empty if unknown
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
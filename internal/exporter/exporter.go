@@ -0,0 +1,100 @@
+package exporter
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// Exporter writes a bookmark tree in one file format
+type Exporter interface {
+	// Name is the format name, e.g. "html", as accepted by export --format
+	Name() string
+	// Extension is the usual file name extension, e.g. ".html"
+	Extension() string
+	// Export writes the folder tree below root
+	Export(w io.Writer, root *models.FolderNode) error
+}
+
+// DirExporter is implemented by exporters that can also write one file per
+// folder into a directory tree mirroring the folders
+type DirExporter interface {
+	Exporter
+	// ExportDir writes the folder tree below root into dir, creating it if needed
+	ExportDir(dir string, root *models.FolderNode) error
+}
+
+// Registry is a set of exporters, looked up by name or file name
+type Registry struct {
+	exporters []Exporter
+}
+
+// NewRegistry creates a registry. When several exporters use the same file
+// name extension, the first one is picked for it.
+func NewRegistry(exporters ...Exporter) *Registry {
+	return &Registry{exporters: exporters}
+}
+
+// DefaultRegistry returns a registry with all supported formats
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		NewHTMLExporter(),
+		NewNativeExporter(),
+		NewMarkdownExporter(),
+		NewCSVExporter(),
+		NewOPMLExporter(),
+		NewChromeExporter(),
+		NewFirefoxExporter(),
+	)
+}
+
+// Names returns the names of all formats
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.exporters))
+	for i, e := range r.exporters {
+		names[i] = e.Name()
+	}
+	return names
+}
+
+// Get returns the exporter for a format name, or nil if there is none
+func (r *Registry) Get(name string) Exporter {
+	for _, e := range r.exporters {
+		if e.Name() == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// ForFile returns the exporter for the extension of a file name, or nil
+func (r *Registry) ForFile(path string) Exporter {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".htm" {
+		ext = ".html"
+	}
+	for _, e := range r.exporters {
+		if e.Extension() == ext {
+			return e
+		}
+	}
+	return nil
+}
+
+// splitToolbar splits root for browsers that keep the bookmarks toolbar
+// apart from the other bookmarks: toolbar holds the contents of the first
+// top-level folder marked as the toolbar (nil if there is none) and rest
+// holds everything else
+func splitToolbar(root *models.FolderNode) (toolbar, rest *models.FolderNode) {
+	rest = &models.FolderNode{Folder: root.Folder, Bookmarks: root.Bookmarks}
+	for _, child := range root.Folders {
+		if toolbar == nil && child.Folder.Toolbar {
+			toolbar = child
+			continue
+		}
+		rest.Folders = append(rest.Folders, child)
+	}
+	return toolbar, rest
+}
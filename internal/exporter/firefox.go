@@ -0,0 +1,207 @@
+package exporter
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// Firefox item types of a bookmarks backup
+const (
+	firefoxTypeBookmark  = 1
+	firefoxTypeContainer = 2
+)
+
+// firefoxItem is a bookmark or folder in a Firefox bookmarks backup
+type firefoxItem struct {
+	GUID         string         `json:"guid"`
+	Title        string         `json:"title"`
+	Index        int            `json:"index"`
+	DateAdded    int64          `json:"dateAdded"`    // microseconds since the Unix epoch
+	LastModified int64          `json:"lastModified"` // microseconds since the Unix epoch
+	ID           int            `json:"id"`
+	TypeCode     int            `json:"typeCode"`
+	Type         string         `json:"type"`
+	Root         string         `json:"root,omitempty"` // only set for root folders
+	Tags         string         `json:"tags,omitempty"` // comma-separated
+	URI          string         `json:"uri,omitempty"`
+	Keyword      string         `json:"keyword,omitempty"`
+	Children     *[]firefoxItem `json:"children,omitempty"` // only set for folders
+}
+
+// firefoxRoot is a root folder of the Firefox bookmarks hierarchy
+type firefoxRoot struct {
+	guid  string
+	title string
+	root  string
+}
+
+// firefoxRoots are the children of the places root, in the order and with
+// the IDs (2 to 6) of a new Firefox profile
+var firefoxRoots = []firefoxRoot{
+	{"menu________", "menu", "bookmarksMenuFolder"},
+	{"toolbar_____", "toolbar", "toolbarFolder"},
+	{"tags________", "tags", "tagsFolder"},
+	{"unfiled_____", "unfiled", "unfiledBookmarksFolder"},
+	{"mobile______", "mobile", "mobileFolder"},
+}
+
+// FirefoxExporter writes a Firefox bookmarks backup, which Firefox restores
+// with "Import and Backup > Restore > Choose File..." or picks up from the
+// bookmarkbackups directory of a profile
+type FirefoxExporter struct{}
+
+// NewFirefoxExporter creates a new Firefox bookmarks backup exporter
+func NewFirefoxExporter() *FirefoxExporter {
+	return &FirefoxExporter{}
+}
+
+// Name returns the format name
+func (e *FirefoxExporter) Name() string {
+	return "firefox-json"
+}
+
+// Extension returns the file name extension. .json files get the native
+// format, so the format is only used when asked for by name.
+func (e *FirefoxExporter) Extension() string {
+	return ".json"
+}
+
+// Export writes root as a Firefox bookmarks backup. The contents of the
+// toolbar folder go to the bookmarks toolbar and everything else to the
+// bookmarks menu, the reverse of FirefoxImporter. Tags and keywords are
+// kept; every item gets a new ID and GUID.
+func (e *FirefoxExporter) Export(w io.Writer, root *models.FolderNode) error {
+	enc := &firefoxEncoder{now: time.Now()}
+	toolbar, rest := splitToolbar(root)
+	contents := map[string]*models.FolderNode{"menu________": rest, "toolbar_____": toolbar}
+
+	places := firefoxItem{
+		GUID:     "root________",
+		ID:       enc.nextID(),
+		TypeCode: firefoxTypeContainer,
+		Type:     "text/x-moz-place-container",
+		Root:     "placesRoot",
+	}
+	places.DateAdded, places.LastModified = enc.times(time.Time{}, time.Time{})
+
+	// Number the roots before their contents, like Firefox does
+	roots := make([]firefoxItem, len(firefoxRoots))
+	for i, r := range firefoxRoots {
+		roots[i] = firefoxItem{
+			GUID:     r.guid,
+			Title:    r.title,
+			Index:    i,
+			ID:       enc.nextID(),
+			TypeCode: firefoxTypeContainer,
+			Type:     "text/x-moz-place-container",
+			Root:     r.root,
+		}
+		roots[i].DateAdded, roots[i].LastModified = enc.times(time.Time{}, time.Time{})
+	}
+	for i := range roots {
+		children := []firefoxItem{}
+		if node := contents[roots[i].GUID]; node != nil {
+			var err error
+			if children, err = enc.children(node); err != nil {
+				return err
+			}
+		}
+		roots[i].Children = &children
+	}
+	places.Children = &roots
+
+	data, err := json.Marshal(places)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// firefoxEncoder numbers the items of a Firefox bookmarks backup
+type firefoxEncoder struct {
+	now    time.Time
+	lastID int
+}
+
+// children returns the items of the bookmarks and subfolders of a folder
+func (e *firefoxEncoder) children(node *models.FolderNode) ([]firefoxItem, error) {
+	items := []firefoxItem{}
+	for _, b := range node.Bookmarks {
+		guid, err := newFirefoxGUID()
+		if err != nil {
+			return nil, err
+		}
+		item := firefoxItem{
+			GUID:     guid,
+			Title:    b.Title,
+			Index:    len(items),
+			ID:       e.nextID(),
+			TypeCode: firefoxTypeBookmark,
+			Type:     "text/x-moz-place",
+			Tags:     strings.Join(b.Tags, ","),
+			URI:      b.URL,
+			Keyword:  b.Keyword,
+		}
+		item.DateAdded, item.LastModified = e.times(b.CreatedAt, b.UpdatedAt)
+		items = append(items, item)
+	}
+	for _, sub := range node.Folders {
+		guid, err := newFirefoxGUID()
+		if err != nil {
+			return nil, err
+		}
+		item := firefoxItem{
+			GUID:     guid,
+			Title:    sub.Folder.Name,
+			Index:    len(items),
+			ID:       e.nextID(),
+			TypeCode: firefoxTypeContainer,
+			Type:     "text/x-moz-place-container",
+		}
+		item.DateAdded, item.LastModified = e.times(sub.Folder.CreatedAt, sub.Folder.UpdatedAt)
+		children, err := e.children(sub)
+		if err != nil {
+			return nil, err
+		}
+		item.Children = &children
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// nextID returns the next item ID. IDs start at 1 and are unique in the file.
+func (e *firefoxEncoder) nextID() int {
+	e.lastID++
+	return e.lastID
+}
+
+// times returns the dateAdded and lastModified of an item in microseconds.
+// An unknown creation time is set to the time of the export, and an unknown
+// modification time to the creation time.
+func (e *firefoxEncoder) times(created, updated time.Time) (int64, int64) {
+	if created.IsZero() {
+		created = e.now
+	}
+	if updated.IsZero() {
+		updated = created
+	}
+	return created.UnixMicro(), updated.UnixMicro()
+}
+
+// newFirefoxGUID returns a random GUID in the Firefox form: 12 characters
+// of URL-safe base64
+func newFirefoxGUID() (string, error) {
+	var b [9]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate GUID: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,124 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// HTMLExporter writes Netscape bookmark files, which every browser imports
+type HTMLExporter struct{}
+
+// NewHTMLExporter creates a new HTML exporter
+func NewHTMLExporter() *HTMLExporter {
+	return &HTMLExporter{}
+}
+
+// Name returns the format name
+func (e *HTMLExporter) Name() string {
+	return "html"
+}
+
+// Extension returns the file name extension
+func (e *HTMLExporter) Extension() string {
+	return ".html"
+}
+
+// Export writes root as a Netscape bookmark file
+func (e *HTMLExporter) Export(w io.Writer, root *models.FolderNode) error {
+	// bufio.Writer keeps the first write error and returns it from Flush
+	bw := bufio.NewWriter(w)
+
+	// Write HTML header
+	fmt.Fprintf(bw, "<!DOCTYPE NETSCAPE-Bookmark-file-1>\n")
+	fmt.Fprintf(bw, "<META HTTP-EQUIV=\"Content-Type\" CONTENT=\"text/html; charset=UTF-8\">\n")
+	fmt.Fprintf(bw, "<TITLE>Bookmarks</TITLE>\n")
+	fmt.Fprintf(bw, "<H1>Bookmarks</H1>\n")
+	fmt.Fprintf(bw, "<DL><p>\n")
+
+	// Write root bookmarks (without folder)
+	for i := range root.Bookmarks {
+		e.writeBookmark(bw, &root.Bookmarks[i])
+	}
+
+	// Write folders recursively
+	for _, folder := range root.Folders {
+		e.writeFolder(bw, folder)
+	}
+
+	// Write HTML footer
+	fmt.Fprintf(bw, "</DL><p>\n")
+	return bw.Flush()
+}
+
+// writeFolder writes a folder and its contents recursively
+func (e *HTMLExporter) writeFolder(w io.Writer, node *models.FolderNode) {
+	// Write folder header
+	attrs := dateAttrs(node.Folder.CreatedAt, node.Folder.UpdatedAt, time.Time{})
+	if node.Folder.Toolbar {
+		attrs += " PERSONAL_TOOLBAR_FOLDER=\"true\""
+	}
+	fmt.Fprintf(w, "    <DT><H3%s>%s</H3>\n", attrs, html.EscapeString(node.Folder.Name))
+	fmt.Fprintf(w, "    <DL><p>\n")
+
+	// Write bookmarks in this folder
+	for i := range node.Bookmarks {
+		e.writeBookmark(w, &node.Bookmarks[i])
+	}
+
+	// Write child folders
+	for _, child := range node.Folders {
+		e.writeFolder(w, child)
+	}
+
+	// Close folder
+	fmt.Fprintf(w, "    </DL><p>\n")
+}
+
+// writeBookmark writes a single bookmark
+func (e *HTMLExporter) writeBookmark(w io.Writer, b *models.Bookmark) {
+	attrs := fmt.Sprintf(" HREF=\"%s\"", html.EscapeString(b.URL))
+
+	// Write icon if available
+	if b.Icon != nil && *b.Icon != "" {
+		attrs += fmt.Sprintf(" ICON=\"%s\"", html.EscapeString(*b.Icon))
+	}
+
+	attrs += dateAttrs(b.CreatedAt, b.UpdatedAt, b.LastVisitedAt)
+
+	if b.Keyword != "" {
+		attrs += fmt.Sprintf(" SHORTCUTURL=\"%s\"", html.EscapeString(b.Keyword))
+	}
+
+	if len(b.Tags) > 0 {
+		attrs += fmt.Sprintf(" TAGS=\"%s\"", html.EscapeString(strings.Join(b.Tags, ",")))
+	}
+
+	fmt.Fprintf(w, "    <DT><A%s>%s</A>\n", attrs, html.EscapeString(b.Title))
+
+	// The description runs until the next tag, so it may span lines
+	if b.Description != "" {
+		fmt.Fprintf(w, "    <DD>%s\n", html.EscapeString(b.Description))
+	}
+}
+
+// dateAttrs returns ADD_DATE, LAST_MODIFIED and LAST_VISIT attributes
+// (unix seconds) for the non-zero timestamps
+func dateAttrs(created, modified, visited time.Time) string {
+	var attrs string
+	if !created.IsZero() {
+		attrs += fmt.Sprintf(" ADD_DATE=\"%d\"", created.Unix())
+	}
+	if !modified.IsZero() {
+		attrs += fmt.Sprintf(" LAST_MODIFIED=\"%d\"", modified.Unix())
+	}
+	if !visited.IsZero() {
+		attrs += fmt.Sprintf(" LAST_VISIT=\"%d\"", visited.Unix())
+	}
+	return attrs
+}
@@ -0,0 +1,192 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// markdownIndex is the file name used for each folder by ExportDir
+const markdownIndex = "index.md"
+
+// MarkdownExporter writes bookmarks as Markdown link lists, one heading per
+// folder. It can also write one file per folder (see ExportDir).
+type MarkdownExporter struct {
+	// FrontMatter adds a YAML front matter block with the title and the
+	// export date to every file
+	FrontMatter bool
+}
+
+// NewMarkdownExporter creates a new Markdown exporter
+func NewMarkdownExporter() *MarkdownExporter {
+	return &MarkdownExporter{}
+}
+
+// Name returns the format name
+func (e *MarkdownExporter) Name() string {
+	return "markdown"
+}
+
+// Extension returns the file name extension
+func (e *MarkdownExporter) Extension() string {
+	return ".md"
+}
+
+// Export writes root as a single Markdown file. Folders become nested
+// headings, starting at level 2 below the "# Bookmarks" title.
+func (e *MarkdownExporter) Export(w io.Writer, root *models.FolderNode) error {
+	bw := bufio.NewWriter(w)
+	e.writeHeader(bw, "Bookmarks")
+	writeMarkdownBookmarks(bw, root.Bookmarks)
+	for _, folder := range root.Folders {
+		e.writeFolder(bw, folder, 2)
+	}
+	return bw.Flush()
+}
+
+// ExportDir writes one Markdown file per folder into dir: dir/index.md for
+// the root and <folder>/index.md in a directory tree mirroring the folders.
+// Each file links to the files of its subfolders. Existing files are
+// overwritten.
+func (e *MarkdownExporter) ExportDir(dir string, root *models.FolderNode) error {
+	return e.writeDir(dir, "Bookmarks", root)
+}
+
+// writeFolder writes a folder heading with its bookmarks, then its
+// subfolders one level deeper
+func (e *MarkdownExporter) writeFolder(w io.Writer, node *models.FolderNode, level int) {
+	// Markdown has six heading levels; deeper folders stay at the last one
+	fmt.Fprintf(w, "\n%s %s\n", strings.Repeat("#", min(level, 6)), markdownText(node.Folder.Name))
+	writeMarkdownBookmarks(w, node.Bookmarks)
+	for _, child := range node.Folders {
+		e.writeFolder(w, child, level+1)
+	}
+}
+
+// writeDir writes the file of one folder into dir and recurses into its
+// subfolders
+func (e *MarkdownExporter) writeDir(dir, title string, node *models.FolderNode) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(dir, markdownIndex))
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(file)
+	e.writeHeader(bw, title)
+	writeMarkdownBookmarks(bw, node.Bookmarks)
+
+	names := folderFileNames(node.Folders)
+	if len(node.Folders) > 0 {
+		fmt.Fprintf(bw, "\n## Folders\n\n")
+		for i, child := range node.Folders {
+			fmt.Fprintf(bw, "- [%s](%s/%s)\n", markdownText(child.Folder.Name), url.PathEscape(names[i]), markdownIndex)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	for i, child := range node.Folders {
+		if err := e.writeDir(filepath.Join(dir, names[i]), child.Folder.Name, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeHeader writes the optional front matter and the title of a file
+func (e *MarkdownExporter) writeHeader(w io.Writer, title string) {
+	if e.FrontMatter {
+		fmt.Fprintf(w, "---\ntitle: %s\ndate: %s\n---\n\n", yamlString(title), time.Now().UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "# %s\n", markdownText(title))
+}
+
+// writeMarkdownBookmarks writes bookmarks as a list of
+// "- [title](url) — description" lines
+func writeMarkdownBookmarks(w io.Writer, bookmarks []models.Bookmark) {
+	if len(bookmarks) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	for _, b := range bookmarks {
+		title := b.Title
+		if title == "" {
+			title = b.URL
+		}
+		line := fmt.Sprintf("- [%s](%s)", markdownText(title), markdownURL(b.URL))
+		// A list item is a single line, so line breaks become spaces
+		if desc := strings.Join(strings.Fields(b.Description), " "); desc != "" {
+			line += " — " + markdownText(desc)
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// folderFileNames returns a directory name for each folder that is safe on
+// all platforms and unique among its siblings
+func folderFileNames(folders []*models.FolderNode) []string {
+	names := make([]string, len(folders))
+	used := make(map[string]bool, len(folders))
+	for i, f := range folders {
+		base := strings.Map(func(r rune) rune {
+			if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+				return '-'
+			}
+			return r
+		}, strings.TrimSpace(f.Folder.Name))
+		base = strings.Trim(base, ". ")
+		if base == "" {
+			base = "folder"
+		}
+
+		name := base
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			name = fmt.Sprintf("%s (%d)", base, n)
+		}
+		used[strings.ToLower(name)] = true
+		names[i] = name
+	}
+	return names
+}
+
+// markdownEscaper escapes the characters that have a meaning in inline Markdown
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`,
+)
+
+// markdownText escapes s for use in headings, link text and list items
+func markdownText(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// markdownURLEscaper percent-encodes the characters that would end a link
+// destination
+var markdownURLEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+// markdownURL returns u for use as a link destination
+func markdownURL(u string) string {
+	return markdownURLEscaper.Replace(u)
+}
+
+// yamlString quotes s as a YAML string. A JSON string is valid YAML.
+func yamlString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
@@ -0,0 +1,33 @@
+package exporter
+
+import (
+	"io"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+	"github.com/dastanaron/bookmarks/internal/native"
+)
+
+// NativeExporter writes the native JSON format, which keeps every field
+// (see docs/json-format.md)
+type NativeExporter struct{}
+
+// NewNativeExporter creates a new native JSON exporter
+func NewNativeExporter() *NativeExporter {
+	return &NativeExporter{}
+}
+
+// Name returns the format name
+func (e *NativeExporter) Name() string {
+	return "json"
+}
+
+// Extension returns the file name extension
+func (e *NativeExporter) Extension() string {
+	return ".json"
+}
+
+// Export writes root as native JSON
+func (e *NativeExporter) Export(w io.Writer, root *models.FolderNode) error {
+	return native.Encode(w, root, time.Now())
+}
@@ -0,0 +1,105 @@
+package exporter
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// opmlDocument is an OPML 2.0 document (http://opml.org/spec2.opml)
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Created string        `xml:"head>dateCreated"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+// opmlOutline is a folder or, with type="link", a bookmark
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Type     string        `xml:"type,attr,omitempty"`
+	URL      string        `xml:"url,attr,omitempty"`
+	Note     string        `xml:"_note,attr,omitempty"`
+	Category string        `xml:"category,attr,omitempty"`
+	Created  string        `xml:"created,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// OPMLExporter writes OPML 2.0 outlines for outliners and feed readers:
+// folders are nested outlines, bookmarks are outlines with type="link"
+type OPMLExporter struct{}
+
+// NewOPMLExporter creates a new OPML exporter
+func NewOPMLExporter() *OPMLExporter {
+	return &OPMLExporter{}
+}
+
+// Name returns the format name
+func (e *OPMLExporter) Name() string {
+	return "opml"
+}
+
+// Extension returns the file name extension
+func (e *OPMLExporter) Extension() string {
+	return ".opml"
+}
+
+// Export writes root as an OPML document. Descriptions go into _note, tags
+// into category ("/tag" per tag) and creation dates into created.
+func (e *OPMLExporter) Export(w io.Writer, root *models.FolderNode) error {
+	doc := opmlDocument{
+		Version: "2.0",
+		Title:   "Bookmarks",
+		Created: time.Now().UTC().Format(time.RFC1123Z),
+		Body:    opmlOutlines(root),
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// opmlOutlines returns the outlines of the bookmarks and subfolders of a folder
+func opmlOutlines(node *models.FolderNode) []opmlOutline {
+	outlines := make([]opmlOutline, 0, len(node.Bookmarks)+len(node.Folders))
+	for _, b := range node.Bookmarks {
+		categories := make([]string, len(b.Tags))
+		for i, t := range b.Tags {
+			categories[i] = "/" + t
+		}
+		outlines = append(outlines, opmlOutline{
+			Text:     b.Title,
+			Type:     "link",
+			URL:      b.URL,
+			Note:     b.Description,
+			Category: strings.Join(categories, ","),
+			Created:  opmlTime(b.CreatedAt),
+		})
+	}
+	for _, child := range node.Folders {
+		outlines = append(outlines, opmlOutline{
+			Text:     child.Folder.Name,
+			Created:  opmlTime(child.Folder.CreatedAt),
+			Outlines: opmlOutlines(child),
+		})
+	}
+	return outlines
+}
+
+// opmlTime formats a timestamp as an RFC 822 date, empty if unknown
+func opmlTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC1123Z)
+}
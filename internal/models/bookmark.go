@@ -1,5 +1,10 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
 // ItemType represents the type of item (bookmark or folder)
 type ItemType string
 
@@ -8,11 +13,55 @@ const (
 	ItemTypeFolder   ItemType = "folder"
 )
 
+// FolderDeletePolicy defines what happens to the contents of a deleted folder
+type FolderDeletePolicy int
+
+const (
+	// FolderDeleteCascade moves the folder to the trash with all its subfolders and bookmarks
+	FolderDeleteCascade FolderDeletePolicy = iota
+	// FolderDeleteMoveToParent moves subfolders and bookmarks to the parent folder
+	// (or the root) and trashes only the folder itself
+	FolderDeleteMoveToParent
+)
+
+// ImportConflictPolicy defines what an import does with a bookmark whose
+// URL already exists
+type ImportConflictPolicy int
+
+const (
+	// ImportOverwrite replaces the existing bookmark's fields with the imported ones
+	ImportOverwrite ImportConflictPolicy = iota
+	// ImportSkipExisting leaves existing bookmarks alone
+	ImportSkipExisting
+	// ImportKeepNewest overwrites only if the imported bookmark was modified later
+	ImportKeepNewest
+	// ImportMergeFields fills only the empty fields of the existing bookmark
+	// and adds the imported tags
+	ImportMergeFields
+	// ImportKeepBoth adds the imported bookmark next to the existing one
+	ImportKeepBoth
+)
+
+// ImportConflictPolicyNames are the names of the policies, in constant order
+var ImportConflictPolicyNames = []string{"overwrite", "skip-existing", "keep-newest", "merge-fields", "keep-both"}
+
+// String returns the policy name, e.g. "keep-newest"
+func (p ImportConflictPolicy) String() string {
+	if p < 0 || int(p) >= len(ImportConflictPolicyNames) {
+		return "unknown"
+	}
+	return ImportConflictPolicyNames[p]
+}
+
 // Folder represents a bookmark folder
 type Folder struct {
-	ID       int
-	Name     string
-	ParentID *int
+	ID        int
+	Name      string
+	ParentID  *int
+	Toolbar   bool      // shown on the browser's bookmarks toolbar
+	CreatedAt time.Time // zero if unknown
+	UpdatedAt time.Time // zero if unknown
+	DeletedAt time.Time // zero unless the folder is in the trash
 }
 
 // Bookmark represents a bookmark entry
@@ -21,9 +70,116 @@ type Bookmark struct {
 	Title       string
 	URL         string
 	Description string
+	Keyword     string  // browser keyword (e.g. "w" for Wikipedia), empty if none
 	Icon        *string // Base64-encoded icon image (nullable)
+	ReadLater   bool    // saved to read later (Pinboard's "toread")
 	FolderID    *int
 	FolderName  *string
+	Tags        []string // nil means "not loaded / leave unchanged" on Update
+	// Timestamps are zero if unknown
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	LastVisitedAt time.Time
+	DeletedAt     time.Time // zero unless the bookmark is in the trash
+}
+
+// FolderNode is a folder with its subfolders and bookmarks. Importers and
+// exporters use a tree of them, rooted at a node with a zero Folder standing
+// for the root. In an imported tree, IDs are only set by formats that carry
+// them, and ParentID and FolderID are not set.
+type FolderNode struct {
+	Folder    Folder
+	Folders   []*FolderNode
+	Bookmarks []Bookmark
+	// BareURLs is set by importers on the root of a tree whose bookmarks
+	// without a title are bare URLs (a URL list). Saving one over an existing
+	// bookmark keeps the existing title, description and folder.
+	BareURLs bool
+	// HasReadLater is set by importers on the root of a tree from a file that
+	// carries read-later flags, which then replace those of existing
+	// bookmarks. Otherwise existing bookmarks keep theirs.
+	HasReadLater bool
+}
+
+// AddFolder appends a new subfolder with the given name and returns it
+func (n *FolderNode) AddFolder(name string) *FolderNode {
+	child := &FolderNode{Folder: Folder{Name: name}}
+	n.Folders = append(n.Folders, child)
+	return child
+}
+
+// Subfolder returns the folder at a slash-separated path below n such as
+// "Work/Infra", adding the missing folders. Names are matched exactly, like
+// FolderService.UpsertPath does in the database. An empty path returns n.
+func (n *FolderNode) Subfolder(path string) *FolderNode {
+	current := n
+	for _, name := range strings.Split(path, "/") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		var next *FolderNode
+		for _, sub := range current.Folders {
+			if sub.Folder.Name == name {
+				next = sub
+				break
+			}
+		}
+		if next == nil {
+			next = current.AddFolder(name)
+		}
+		current = next
+	}
+	return current
+}
+
+// FindFolder returns the node of the folder with the given ID below n, or
+// nil if there is none
+func (n *FolderNode) FindFolder(id int) *FolderNode {
+	for _, sub := range n.Folders {
+		if sub.Folder.ID == id {
+			return sub
+		}
+		if found := sub.FindFolder(id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// Filter returns a copy of the tree below n with only the bookmarks for
+// which keep returns true, and only the folders that still contain any
+func (n *FolderNode) Filter(keep func(b *Bookmark) bool) *FolderNode {
+	filtered := &FolderNode{Folder: n.Folder}
+	for i := range n.Bookmarks {
+		if keep(&n.Bookmarks[i]) {
+			filtered.Bookmarks = append(filtered.Bookmarks, n.Bookmarks[i])
+		}
+	}
+	for _, sub := range n.Folders {
+		if f := sub.Filter(keep); len(f.Bookmarks) > 0 || len(f.Folders) > 0 {
+			filtered.Folders = append(filtered.Folders, f)
+		}
+	}
+	return filtered
+}
+
+// Count returns the number of folders and bookmarks below n, not counting n itself
+func (n *FolderNode) Count() (folders, bookmarks int) {
+	bookmarks = len(n.Bookmarks)
+	for _, sub := range n.Folders {
+		f, b := sub.Count()
+		folders += f + 1
+		bookmarks += b
+	}
+	return folders, bookmarks
+}
+
+// Tag represents a label that can be attached to any number of bookmarks
+type Tag struct {
+	ID    int
+	Name  string
+	Count int // number of bookmarks with this tag
 }
 
 // Item represents a unified item that can be either a bookmark or a folder
@@ -31,9 +187,17 @@ type Bookmark struct {
 type Item struct {
 	Type        ItemType // "bookmark" or "folder"
 	ID          int
-	Name        string  // Title for bookmarks, Name for folders
-	URL         *string // Only for bookmarks, nil for folders
-	Description *string // Only for bookmarks, nil for folders
-	Icon        *string // Only for bookmarks, nil for folders
-	ParentID    *int    // folder_id for bookmarks, parent_id for folders
+	Name        string   // Title for bookmarks, Name for folders
+	URL         *string  // Only for bookmarks, nil for folders
+	Description *string  // Only for bookmarks, nil for folders
+	Icon        *string  // Only for bookmarks, nil for folders
+	Keyword     string   // Only for bookmarks, empty if none
+	ReadLater   bool     // Only for bookmarks
+	ParentID    *int     // folder_id for bookmarks, parent_id for folders
+	Tags        []string // Only for bookmarks, nil for folders
+	// Timestamps are zero if unknown or not set, LastVisitedAt is always zero for folders
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	LastVisitedAt time.Time
+	DeletedAt     time.Time // zero unless the item is in the trash
 }
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// webKitEpochOffset is the number of microseconds between 1601-01-01 (the
+// WebKit/Windows epoch used by Chromium) and the Unix epoch
+const webKitEpochOffset = 11644473600 * 1000000
+
+// WebKitTime formats t as a Chromium timestamp: microseconds since
+// 1601-01-01 as a decimal string
+func WebKitTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixMicro()+webKitEpochOffset, 10)
+}
+
+// ParseWebKitTime parses a Chromium timestamp (microseconds since 1601-01-01
+// as a decimal string). Returns zero time for missing, zero or invalid values.
+func ParseWebKitTime(val string) time.Time {
+	n, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+	if err != nil || n <= webKitEpochOffset {
+		return time.Time{}
+	}
+	return time.UnixMicro(n - webKitEpochOffset)
+}
@@ -0,0 +1,168 @@
+package native
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// The native JSON format is a lossless copy of the bookmark tree, used for
+// backups and by other tools. See docs/json-format.md for the schema.
+// Readers accept any version up to Version; fields added later must be
+// optional, a breaking change needs a new version.
+const (
+	FormatName = "bookmarks-cli"
+	Version    = 1
+)
+
+// File is the top level of a native JSON file
+type File struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Root       Folder    `json:"root"`
+}
+
+// Folder is a folder with its contents. The root folder has no ID and name.
+type Folder struct {
+	ID        int        `json:"id,omitempty"`
+	Name      string     `json:"name,omitempty"`
+	Toolbar   bool       `json:"toolbar,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	Folders   []Folder   `json:"folders"`
+	Bookmarks []Bookmark `json:"bookmarks"`
+}
+
+// Bookmark is a bookmark. Missing timestamps are unknown.
+type Bookmark struct {
+	ID            int        `json:"id,omitempty"`
+	Title         string     `json:"title"`
+	URL           string     `json:"url"`
+	Description   string     `json:"description,omitempty"`
+	Keyword       string     `json:"keyword,omitempty"`
+	ReadLater     bool       `json:"read_later,omitempty"`
+	Icon          *string    `json:"icon,omitempty"`
+	Tags          []string   `json:"tags"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+	LastVisitedAt *time.Time `json:"last_visited_at,omitempty"`
+}
+
+// Encode writes root as an indented native JSON file
+func Encode(w io.Writer, root *models.FolderNode, exportedAt time.Time) error {
+	file := File{
+		Format:     FormatName,
+		Version:    Version,
+		ExportedAt: exportedAt.UTC(),
+		Root:       fromNode(root),
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(file)
+}
+
+// Decode reads a native JSON file and returns its tree
+func Decode(r io.Reader) (*models.FolderNode, error) {
+	var file File
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if file.Format != FormatName {
+		return nil, fmt.Errorf("not a %s file (format %q)", FormatName, file.Format)
+	}
+	if file.Version < 1 || file.Version > Version {
+		return nil, fmt.Errorf("unsupported version %d, this build reads up to version %d", file.Version, Version)
+	}
+	return toNode(file.Root), nil
+}
+
+func fromNode(n *models.FolderNode) Folder {
+	f := Folder{
+		ID:        n.Folder.ID,
+		Name:      n.Folder.Name,
+		Toolbar:   n.Folder.Toolbar,
+		CreatedAt: optionalTime(n.Folder.CreatedAt),
+		UpdatedAt: optionalTime(n.Folder.UpdatedAt),
+		Folders:   make([]Folder, 0, len(n.Folders)),
+		Bookmarks: make([]Bookmark, 0, len(n.Bookmarks)),
+	}
+	for _, sub := range n.Folders {
+		f.Folders = append(f.Folders, fromNode(sub))
+	}
+	for _, b := range n.Bookmarks {
+		tags := b.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		f.Bookmarks = append(f.Bookmarks, Bookmark{
+			ID:            b.ID,
+			Title:         b.Title,
+			URL:           b.URL,
+			Description:   b.Description,
+			Keyword:       b.Keyword,
+			ReadLater:     b.ReadLater,
+			Icon:          b.Icon,
+			Tags:          tags,
+			CreatedAt:     optionalTime(b.CreatedAt),
+			UpdatedAt:     optionalTime(b.UpdatedAt),
+			LastVisitedAt: optionalTime(b.LastVisitedAt),
+		})
+	}
+	return f
+}
+
+func toNode(f Folder) *models.FolderNode {
+	n := &models.FolderNode{Folder: models.Folder{
+		ID:        f.ID,
+		Name:      f.Name,
+		Toolbar:   f.Toolbar,
+		CreatedAt: timeValue(f.CreatedAt),
+		UpdatedAt: timeValue(f.UpdatedAt),
+	}}
+	for _, sub := range f.Folders {
+		n.Folders = append(n.Folders, toNode(sub))
+	}
+	for _, b := range f.Bookmarks {
+		if b.URL == "" {
+			continue
+		}
+		tags := b.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		n.Bookmarks = append(n.Bookmarks, models.Bookmark{
+			ID:            b.ID,
+			Title:         b.Title,
+			URL:           b.URL,
+			Description:   b.Description,
+			Keyword:       b.Keyword,
+			ReadLater:     b.ReadLater,
+			Icon:          b.Icon,
+			Tags:          tags,
+			CreatedAt:     timeValue(b.CreatedAt),
+			UpdatedAt:     timeValue(b.UpdatedAt),
+			LastVisitedAt: timeValue(b.LastVisitedAt),
+		})
+	}
+	return n
+}
+
+// optionalTime returns nil for an unknown (zero) time
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+func timeValue(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// BukuImporter reads the bookmarks.db database of buku
+// (https://github.com/jarun/buku), with titles, descriptions and tags
+type BukuImporter struct {
+	// TagFolders puts each bookmark into a folder named after its first tag
+	// instead of keeping all tags as tags
+	TagFolders bool
+}
+
+// NewBukuImporter creates a new buku importer
+func NewBukuImporter() *BukuImporter {
+	return &BukuImporter{}
+}
+
+// Name returns the format name
+func (p *BukuImporter) Name() string {
+	return "buku"
+}
+
+// Detect reports whether head is the beginning of an SQLite database with
+// buku's bookmarks table in its schema (on the first page)
+func (p *BukuImporter) Detect(head []byte) bool {
+	return bytes.HasPrefix(head, sqliteHeader) &&
+		bytes.Contains(head, []byte("CREATE TABLE bookmarks")) &&
+		bytes.Contains(head, []byte("metadata text"))
+}
+
+// Parse reads a buku database from r. buku has no folders and no dates:
+// all bookmarks are in the root unless TagFolders is set.
+func (p *BukuImporter) Parse(r io.Reader) (*models.FolderNode, error) {
+	tmpDir, err := os.MkdirTemp("", "bookmarks-buku-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	copyPath := filepath.Join(tmpDir, "bookmarks.db")
+	if err := writeFile(copyPath, r); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+(&url.URL{Path: copyPath}).EscapedPath()+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	posts, err := readBukuPosts(db)
+	if err != nil {
+		return nil, fmt.Errorf("not a buku database: %w", err)
+	}
+	return postsTree(posts, p.TagFolders), nil
+}
+
+// readBukuPosts returns the rows of buku's bookmarks table in ID order
+func readBukuPosts(db *sql.DB) ([]post, error) {
+	rows, err := db.Query(`
+		SELECT URL, COALESCE(metadata, ''), COALESCE(tags, ''), COALESCE(desc, '')
+		FROM bookmarks
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []post
+	for rows.Next() {
+		var ps post
+		var tags string
+		if err := rows.Scan(&ps.URL, &ps.Title, &tags, &ps.Extended); err != nil {
+			return nil, err
+		}
+		ps.Tags = bukuTags(tags)
+		posts = append(posts, ps)
+	}
+	return posts, rows.Err()
+}
+
+// bukuTags splits buku's tags column, a comma-delimited list with a comma
+// at both ends such as ",go,web dev,"
+func bukuTags(s string) []string {
+	tags := []string{}
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// chromeNode is a bookmark or folder in a Chromium "Bookmarks" file
+type chromeNode struct {
+	Type         string       `json:"type"` // "url" or "folder"
+	Name         string       `json:"name"`
+	URL          string       `json:"url"`
+	DateAdded    string       `json:"date_added"`
+	DateModified string       `json:"date_modified"`
+	DateLastUsed string       `json:"date_last_used"`
+	Children     []chromeNode `json:"children"`
+}
+
+// chromeFile is the top level of a Chromium "Bookmarks" file
+type chromeFile struct {
+	Roots struct {
+		BookmarkBar *chromeNode `json:"bookmark_bar"`
+		Other       *chromeNode `json:"other"`
+		Synced      *chromeNode `json:"synced"`
+	} `json:"roots"`
+}
+
+// ChromeImporter reads the "Bookmarks" file of a Chromium-based browser
+// (Chrome, Chromium, Edge, Brave, Vivaldi, ...)
+type ChromeImporter struct{}
+
+// NewChromeImporter creates a new Chromium bookmarks importer
+func NewChromeImporter() *ChromeImporter {
+	return &ChromeImporter{}
+}
+
+// Name returns the format name
+func (p *ChromeImporter) Name() string {
+	return "chrome-json"
+}
+
+// Detect reports whether head looks like a Chromium "Bookmarks" file
+func (p *ChromeImporter) Detect(head []byte) bool {
+	s := strings.TrimLeft(string(head), " \t\r\n\ufeff")
+	return strings.HasPrefix(s, "{") && strings.Contains(s, `"roots"`)
+}
+
+// Parse parses a Chromium "Bookmarks" file and returns its folder tree.
+// Like Chrome's own HTML export, the bookmarks bar and mobile bookmarks
+// become folders and "Other bookmarks" end up in the root.
+func (p *ChromeImporter) Parse(r io.Reader) (*models.FolderNode, error) {
+	var file chromeFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid bookmarks JSON: %w", err)
+	}
+	if file.Roots.BookmarkBar == nil && file.Roots.Other == nil && file.Roots.Synced == nil {
+		return nil, fmt.Errorf("invalid bookmarks JSON: no roots found")
+	}
+
+	root := &models.FolderNode{}
+	roots := []struct {
+		node        *chromeNode
+		defaultName string
+		asFolder    bool
+	}{
+		{file.Roots.BookmarkBar, "Bookmarks bar", true},
+		{file.Roots.Other, "Other bookmarks", false},
+		{file.Roots.Synced, "Mobile bookmarks", true},
+	}
+	for _, top := range roots {
+		if top.node == nil || len(top.node.Children) == 0 {
+			continue
+		}
+		if !top.asFolder {
+			addChromeChildren(root, top.node.Children)
+			continue
+		}
+		node := *top.node
+		if node.Name == "" {
+			node.Name = top.defaultName
+		}
+		folder := addChromeFolder(root, node)
+		folder.Folder.Toolbar = top.node == file.Roots.BookmarkBar
+	}
+	return root, nil
+}
+
+// addChromeFolder adds a folder with its contents to parent and returns it
+func addChromeFolder(parent *models.FolderNode, n chromeNode) *models.FolderNode {
+	name := strings.TrimSpace(n.Name)
+	if name == "" {
+		name = "Untitled"
+	}
+
+	folder := parent.AddFolder(name)
+	folder.Folder.CreatedAt = models.ParseWebKitTime(n.DateAdded)
+	folder.Folder.UpdatedAt = models.ParseWebKitTime(n.DateModified)
+	addChromeChildren(folder, n.Children)
+	return folder
+}
+
+func addChromeChildren(parent *models.FolderNode, children []chromeNode) {
+	for _, child := range children {
+		switch child.Type {
+		case "folder":
+			addChromeFolder(parent, child)
+		case "url":
+			if child.URL == "" {
+				continue
+			}
+			parent.Bookmarks = append(parent.Bookmarks, models.Bookmark{
+				Title:         strings.TrimSpace(child.Name),
+				URL:           child.URL,
+				CreatedAt:     models.ParseWebKitTime(child.DateAdded),
+				UpdatedAt:     models.ParseWebKitTime(child.DateModified),
+				LastVisitedAt: models.ParseWebKitTime(child.DateLastUsed),
+			})
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+	"github.com/dastanaron/bookmarks/internal/service"
+)
+
+// CSVFields are the bookmark fields a CSV column can be mapped to, in the
+// column order written by the CSV exporter. IDs are only read from the
+// exporter's own files, recognized by a header of exactly these names, so
+// the IDs of other tools don't take the place of ours.
+var CSVFields = []string{
+	"id", "title", "url", "folder", "tags", "description", "keyword",
+	"created_at", "updated_at", "last_visited_at", "read_later",
+}
+
+// csvAliases maps the lower-case header names used by other tools (Raindrop,
+// Pinboard, Linkwarden, spreadsheets) to CSVFields
+var csvAliases = map[string]string{
+	"link":          "url",
+	"href":          "url",
+	"address":       "url",
+	"uri":           "url",
+	"name":          "title",
+	"collection":    "folder",
+	"path":          "folder",
+	"category":      "folder",
+	"tag":           "tags",
+	"labels":        "tags",
+	"note":          "description",
+	"notes":         "description",
+	"excerpt":       "description",
+	"extended":      "description",
+	"comment":       "description",
+	"shortcut":      "keyword",
+	"created":       "created_at",
+	"added":         "created_at",
+	"add_date":      "created_at",
+	"date":          "created_at",
+	"time":          "created_at",
+	"updated":       "updated_at",
+	"modified":      "updated_at",
+	"last_modified": "updated_at",
+	"last_visit":    "last_visited_at",
+	"visited":       "last_visited_at",
+	"toread":        "read_later",
+	"to_read":       "read_later",
+}
+
+// CSVColumns maps field names (see CSVFields) to zero-based column indexes
+type CSVColumns map[string]int
+
+// ParseCSVColumns parses a column mapping such as "url=1,title=2,folder=3",
+// with one-based column numbers as shown by spreadsheets. Field names may
+// also be the header aliases of other tools, e.g. "link" for "url".
+func ParseCSVColumns(spec string) (CSVColumns, error) {
+	cols := CSVColumns{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, num, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid column %q, expected field=number", part)
+		}
+		field := csvField(name)
+		if field == "" {
+			return nil, fmt.Errorf("unknown field %q (expected one of: %s)", name, strings.Join(CSVFields, ", "))
+		}
+		if field == "id" {
+			return nil, errors.New("IDs are only read from the CSV exports of this program, not with --columns")
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(num))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid column number %q for %s, columns start at 1", num, field)
+		}
+		if _, dup := cols[field]; dup {
+			return nil, fmt.Errorf("field %s is mapped twice", field)
+		}
+		cols[field] = n - 1
+	}
+	if _, ok := cols["url"]; !ok {
+		return nil, errors.New("no column for url")
+	}
+	return cols, nil
+}
+
+// csvField returns the field for a column name or header, or "" if unknown
+func csvField(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, f := range CSVFields {
+		if f == name {
+			return f
+		}
+	}
+	return csvAliases[name]
+}
+
+// CSVImporter reads bookmarks from CSV files. Folder columns hold
+// slash-separated paths such as "Work/Infra/K8s", which are expanded into
+// the folder tree.
+type CSVImporter struct {
+	// Columns maps fields to columns. If nil, they are taken from the
+	// header row, which is then required.
+	Columns CSVColumns
+}
+
+// NewCSVImporter creates a new CSV importer that reads the header row
+func NewCSVImporter() *CSVImporter {
+	return &CSVImporter{}
+}
+
+// Name returns the format name
+func (p *CSVImporter) Name() string {
+	return "csv"
+}
+
+// Detect reports whether the first line of head is CSV with several
+// columns and either a URL or a header with a URL column
+func (p *CSVImporter) Detect(head []byte) bool {
+	head = bytes.TrimPrefix(head, []byte("\ufeff"))
+	if len(head) == 0 || head[0] == '<' || head[0] == '{' {
+		return false
+	}
+	line, _, _ := bytes.Cut(head, []byte("\n"))
+	record, err := newCSVReader(bytes.NewReader(line)).Read()
+	if err != nil || len(record) < 2 {
+		return false
+	}
+	for _, field := range record {
+		if csvField(field) == "url" || looksLikeURL(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses a CSV file and returns its folder tree. Rows without a URL
+// are skipped.
+func (p *CSVImporter) Parse(r io.Reader) (*models.FolderNode, error) {
+	reader := newCSVReader(r)
+	first, err := reader.Read()
+	if err == io.EOF {
+		return &models.FolderNode{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(first) > 0 {
+		first[0] = strings.TrimPrefix(first[0], "\ufeff")
+	}
+
+	root := &models.FolderNode{}
+	cols := p.Columns
+	if cols == nil {
+		if cols = headerColumns(first); cols == nil {
+			return nil, errors.New("no header row with a url column, map the columns with --columns (e.g. url=1,title=2)")
+		}
+		if !ownCSVHeader(first) {
+			delete(cols, "id")
+		}
+	} else if i := cols["url"]; i < len(first) && looksLikeURL(first[i]) {
+		// A header row is recognized by not having a URL in the URL column
+		p.addRecord(root, cols, first)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		p.addRecord(root, cols, record)
+	}
+	_, root.HasReadLater = cols["read_later"]
+	return root, nil
+}
+
+// ownCSVHeader reports whether header is that of the CSV exporter
+func ownCSVHeader(header []string) bool {
+	if len(header) != len(CSVFields) {
+		return false
+	}
+	for i, name := range header {
+		if strings.TrimSpace(name) != CSVFields[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// addRecord adds the bookmark in a CSV record to its folder below root
+func (p *CSVImporter) addRecord(root *models.FolderNode, cols CSVColumns, record []string) {
+	field := func(name string) (string, bool) {
+		i, ok := cols[name]
+		if !ok || i >= len(record) {
+			return "", false
+		}
+		return strings.TrimSpace(record[i]), true
+	}
+
+	b := models.Bookmark{}
+	if b.URL, _ = field("url"); b.URL == "" {
+		return
+	}
+	if id, _ := field("id"); id != "" {
+		b.ID, _ = strconv.Atoi(id)
+	}
+	if b.Title, _ = field("title"); b.Title == "" {
+		b.Title = b.URL
+	}
+	b.Description, _ = field("description")
+	b.Keyword, _ = field("keyword")
+	// Without a tags column, Tags stays nil and existing tags are kept
+	if tags, ok := field("tags"); ok {
+		b.Tags = service.ParseTags(tags)
+	}
+	if v, _ := field("created_at"); v != "" {
+		b.CreatedAt = parseCSVTime(v)
+	}
+	if v, _ := field("updated_at"); v != "" {
+		b.UpdatedAt = parseCSVTime(v)
+	}
+	if v, _ := field("last_visited_at"); v != "" {
+		b.LastVisitedAt = parseCSVTime(v)
+	}
+	if v, _ := field("read_later"); v != "" {
+		b.ReadLater = parseCSVBool(v)
+	}
+
+	path, _ := field("folder")
+	folder := root.Subfolder(path)
+	folder.Bookmarks = append(folder.Bookmarks, b)
+}
+
+// headerColumns maps the recognized names in a header row to their columns,
+// or returns nil if there is no URL column. The first column wins for
+// fields with several candidates (e.g. Raindrop's "note" and "excerpt").
+func headerColumns(header []string) CSVColumns {
+	cols := CSVColumns{}
+	for i, name := range header {
+		if field := csvField(name); field != "" {
+			if _, ok := cols[field]; !ok {
+				cols[field] = i
+			}
+		}
+	}
+	if _, ok := cols["url"]; !ok {
+		return nil
+	}
+	return cols
+}
+
+// newCSVReader returns a lenient CSV reader: rows may have different
+// numbers of fields and quotes may appear inside unquoted fields
+func newCSVReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	return reader
+}
+
+// looksLikeURL reports whether s is an absolute URL such as "https://go.dev"
+func looksLikeURL(s string) bool {
+	u, err := url.Parse(strings.TrimSpace(s))
+	return err == nil && u.Scheme != "" && (u.Host != "" || u.Opaque != "")
+}
+
+// csvTimeLayouts are the date formats accepted in CSV files, besides unix
+// timestamps
+var csvTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseCSVTime parses a date as written by spreadsheets and bookmark
+// services. Returns zero time for unrecognized values.
+func parseCSVTime(val string) time.Time {
+	for _, layout := range csvTimeLayouts {
+		if t, err := time.Parse(layout, val); err == nil {
+			return t
+		}
+	}
+	return parseTimestamp(val)
+}
+
+// parseCSVBool parses a yes/no value such as "true", "yes" or "1"
+func parseCSVBool(val string) bool {
+	switch strings.ToLower(val) {
+	case "true", "yes", "y", "1":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/exporter"
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// csvBookmarks lists the bookmarks below root, one per line with their
+// folder path and the fields a CSV file can carry
+func csvBookmarks(root *models.FolderNode) []string {
+	var lines []string
+	var walk func(n *models.FolderNode, path string)
+	walk = func(n *models.FolderNode, path string) {
+		for _, b := range n.Bookmarks {
+			lines = append(lines, fmt.Sprintf("%s|%d|%s|%s|%s|%s|%q|later=%v|%s",
+				path, b.ID, b.Title, b.URL, b.Description, b.Keyword, b.Tags, b.ReadLater,
+				b.CreatedAt.UTC().Format(time.RFC3339)))
+		}
+		for _, sub := range n.Folders {
+			walk(sub, path+"/"+sub.Folder.Name)
+		}
+	}
+	walk(root, "")
+	return lines
+}
+
+func TestCSVImporter(t *testing.T) {
+	tests := []struct {
+		name         string
+		columns      string // --columns, empty to read the header
+		csv          string
+		want         []string
+		hasReadLater bool
+	}{
+		{
+			name: "header aliases",
+			csv: "Link,Name,Collection,Labels,Note,Created,ToRead\n" +
+				"https://go.dev/,Go,Dev/Lang,\"go,lang\",The Go site,2024-03-01,yes\n" +
+				",Skipped,,,,,\n",
+			want:         []string{`/Dev/Lang|0|Go|https://go.dev/|The Go site||["go" "lang"]|later=true|2024-03-01T00:00:00Z`},
+			hasReadLater: true,
+		},
+		{
+			name: "missing title and tags",
+			csv:  "url,folder\nhttps://go.dev/,\n",
+			want: []string{`|0|https://go.dev/|https://go.dev/|||[]|later=false|0001-01-01T00:00:00Z`},
+		},
+		{
+			name: "own export keeps IDs",
+			csv: strings.Join(CSVFields, ",") + "\n" +
+				"7,Go,https://go.dev/,Dev,go,,g,2024-03-01T00:00:00Z,,,true\n",
+			want:         []string{`/Dev|7|Go|https://go.dev/||g|["go"]|later=true|2024-03-01T00:00:00Z`},
+			hasReadLater: true,
+		},
+		{
+			name: "foreign IDs are ignored",
+			csv:  "id,title,url,excerpt\n12345,Go,https://go.dev/,Site\n",
+			want: []string{`|0|Go|https://go.dev/|Site||[]|later=false|0001-01-01T00:00:00Z`},
+		},
+		{
+			name:    "columns with a header row",
+			columns: "url=2,title=1,folder=3",
+			csv:     "Title,Address,Where\nGo,https://go.dev/,Dev\n",
+			want:    []string{`/Dev|0|Go|https://go.dev/|||[]|later=false|0001-01-01T00:00:00Z`},
+		},
+		{
+			name:    "columns without a header row",
+			columns: "link=1,name=2,notes=3",
+			csv:     "https://go.dev/,Go,Site\nhttps://pkg.go.dev/,Packages,\n",
+			want: []string{
+				`|0|Go|https://go.dev/|Site||[]|later=false|0001-01-01T00:00:00Z`,
+				`|0|Packages|https://pkg.go.dev/|||[]|later=false|0001-01-01T00:00:00Z`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewCSVImporter()
+			if tt.columns != "" {
+				cols, err := ParseCSVColumns(tt.columns)
+				if err != nil {
+					t.Fatal(err)
+				}
+				p.Columns = cols
+			}
+			root, err := p.Parse(strings.NewReader(tt.csv))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := csvBookmarks(root); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bookmarks =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if root.HasReadLater != tt.hasReadLater {
+				t.Errorf("HasReadLater = %v, want %v", root.HasReadLater, tt.hasReadLater)
+			}
+		})
+	}
+}
+
+func TestParseCSVColumns(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    CSVColumns
+		wantErr bool
+	}{
+		{spec: "url=1,title=2,folder=3", want: CSVColumns{"url": 0, "title": 1, "folder": 2}},
+		{spec: " Link = 2 , note=1 ", want: CSVColumns{"url": 1, "description": 0}},
+		{spec: "toread=3,url=1", want: CSVColumns{"url": 0, "read_later": 2}},
+		{spec: "title=1", wantErr: true},        // no url
+		{spec: "url=1,colour=2", wantErr: true}, // unknown field
+		{spec: "url=0", wantErr: true},          // columns start at 1
+		{spec: "url=1,link=2", wantErr: true},   // mapped twice
+		{spec: "url=1,title", wantErr: true},    // no number
+		{spec: "id=1,url=2", wantErr: true},     // IDs only from own exports
+	}
+	for _, tt := range tests {
+		got, err := ParseCSVColumns(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCSVColumns(%q) error = %v, want error %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseCSVColumns(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	root := &models.FolderNode{}
+	dev := root.AddFolder("Dev")
+	dev.Bookmarks = []models.Bookmark{{
+		ID:          3,
+		Title:       "Go, the language",
+		URL:         "https://go.dev/",
+		Description: "Line one\nline two",
+		Keyword:     "go",
+		Tags:        []string{"go", "lang"},
+		ReadLater:   true,
+		CreatedAt:   time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+	}}
+	root.Bookmarks = []models.Bookmark{{ID: 4, Title: "Example", URL: "https://example.com/", Tags: []string{}}}
+
+	var buf bytes.Buffer
+	if err := exporter.NewCSVExporter().Export(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := NewCSVImporter().Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := csvBookmarks(imported), csvBookmarks(root); !reflect.DeepEqual(got, want) {
+		t.Errorf("after the round trip\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !imported.HasReadLater {
+		t.Error("HasReadLater = false for an export with a read_later column")
+	}
+}
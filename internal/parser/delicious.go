@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// deliciousPosts is the root element of a Delicious-style XML export, which
+// Pinboard still offers (https://api.pinboard.in/v1/posts/all)
+type deliciousPosts struct {
+	Posts []struct {
+		Href        string `xml:"href,attr"`
+		Description string `xml:"description,attr"`
+		Extended    string `xml:"extended,attr"`
+		Tag         string `xml:"tag,attr"`
+		Time        string `xml:"time,attr"`
+		ToRead      string `xml:"toread,attr"`
+	} `xml:"post"`
+}
+
+// DeliciousImporter reads the XML posts format of Delicious and Pinboard
+type DeliciousImporter struct {
+	// TagFolders puts each bookmark into a folder named after its first tag
+	// instead of keeping all tags as tags
+	TagFolders bool
+}
+
+// NewDeliciousImporter creates a new Delicious XML importer
+func NewDeliciousImporter() *DeliciousImporter {
+	return &DeliciousImporter{}
+}
+
+// Name returns the format name
+func (p *DeliciousImporter) Name() string {
+	return "delicious"
+}
+
+// Detect reports whether head is XML with a <posts> root element
+func (p *DeliciousImporter) Detect(head []byte) bool {
+	head = bytes.TrimLeft(head, " \t\r\n\ufeff")
+	return bytes.HasPrefix(head, []byte("<posts")) ||
+		(bytes.HasPrefix(head, []byte("<?xml")) && bytes.Contains(head, []byte("<posts")))
+}
+
+// Parse parses a Delicious XML export and returns its folder tree
+func (p *DeliciousImporter) Parse(r io.Reader) (*models.FolderNode, error) {
+	var file deliciousPosts
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid Delicious XML: %w", err)
+	}
+
+	posts := make([]post, len(file.Posts))
+	for i, e := range file.Posts {
+		posts[i] = post{
+			URL:      e.Href,
+			Title:    e.Description,
+			Extended: e.Extended,
+			Tags:     strings.Fields(e.Tag),
+			Time:     e.Time,
+			ToRead:   e.ToRead,
+		}
+	}
+	return postsTree(posts, p.TagFolders), nil
+}
@@ -0,0 +1,310 @@
+package parser
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteHeader starts every SQLite database file
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// Firefox bookmark types (moz_bookmarks.type)
+const (
+	firefoxTypeBookmark = 1
+	firefoxTypeFolder   = 2
+)
+
+// firefoxRoots maps the GUIDs of the Firefox root folders to the folder they
+// are imported into. Like Firefox's own HTML export, the bookmarks menu ends up
+// in the root (empty name) and the other roots become folders.
+var firefoxRoots = []struct {
+	guid string
+	name string
+}{
+	{"menu________", ""},
+	{firefoxToolbarRoot, "Bookmarks Toolbar"},
+	{"unfiled_____", "Other Bookmarks"},
+	{"mobile______", "Mobile Bookmarks"},
+}
+
+// firefoxToolbarRoot is the GUID of the bookmarks toolbar folder
+const firefoxToolbarRoot = "toolbar_____"
+
+// firefoxTagsRoot is the GUID of the folder holding one subfolder per tag
+const firefoxTagsRoot = "tags________"
+
+// firefoxEntry is a row of moz_bookmarks joined with moz_places
+type firefoxEntry struct {
+	id           int64
+	typ          int
+	parent       int64
+	placeID      sql.NullInt64
+	title        string
+	url          string
+	guid         string
+	dateAdded    sql.NullInt64
+	lastModified sql.NullInt64
+	lastVisit    sql.NullInt64
+}
+
+// FirefoxImporter reads the bookmarks of a Firefox profile from its
+// places.sqlite database, with their tags and keywords. The database is
+// copied first, so it can be read while Firefox is running and is never
+// modified.
+type FirefoxImporter struct{}
+
+// NewFirefoxImporter creates a new Firefox importer
+func NewFirefoxImporter() *FirefoxImporter {
+	return &FirefoxImporter{}
+}
+
+// Name returns the format name
+func (p *FirefoxImporter) Name() string {
+	return "firefox"
+}
+
+// Detect reports whether head is the beginning of an SQLite database whose
+// schema (on the first page) has the moz_places and moz_bookmarks tables
+func (p *FirefoxImporter) Detect(head []byte) bool {
+	return bytes.HasPrefix(head, sqliteHeader) &&
+		bytes.Contains(head, []byte("moz_places")) &&
+		bytes.Contains(head, []byte("moz_bookmarks"))
+}
+
+// FirefoxPlacesPath returns the places database of a Firefox profile
+// directory, or path itself if it is not a directory
+func FirefoxPlacesPath(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, "places.sqlite")
+	}
+	return path
+}
+
+// Parse reads a places.sqlite database from r
+func (p *FirefoxImporter) Parse(r io.Reader) (*models.FolderNode, error) {
+	tmpDir, err := os.MkdirTemp("", "bookmarks-places-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	copyPath := filepath.Join(tmpDir, "places.sqlite")
+	if err := writeFile(copyPath, r); err != nil {
+		return nil, err
+	}
+	return parsePlaces(copyPath)
+}
+
+// ParseFile reads a copy of the places.sqlite database at path (which may
+// also be the profile directory) together with its write-ahead log
+func (p *FirefoxImporter) ParseFile(path string) (*models.FolderNode, error) {
+	path = FirefoxPlacesPath(path)
+
+	tmpDir, err := os.MkdirTemp("", "bookmarks-places-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Firefox keeps recent changes in the write-ahead log, copy it too
+	copyPath := filepath.Join(tmpDir, "places.sqlite")
+	if err := copyFile(path, copyPath); err != nil {
+		return nil, err
+	}
+	if err := copyFile(path+"-wal", copyPath+"-wal"); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return parsePlaces(copyPath)
+}
+
+// parsePlaces opens a places database read-only and returns its folder tree
+func parsePlaces(path string) (*models.FolderNode, error) {
+	db, err := sql.Open("sqlite3", "file:"+(&url.URL{Path: path}).EscapedPath()+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	entries, err := readFirefoxEntries(db)
+	if err != nil {
+		return nil, fmt.Errorf("not a Firefox places database: %w", err)
+	}
+	keywords, err := readFirefoxKeywords(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keywords: %w", err)
+	}
+
+	children := make(map[int64][]*firefoxEntry)
+	byGUID := make(map[string]*firefoxEntry)
+	for i := range entries {
+		e := &entries[i]
+		children[e.parent] = append(children[e.parent], e)
+		byGUID[e.guid] = e
+	}
+
+	// Tags are folders below the tags root, containing one entry per tagged URL
+	tags := make(map[int64][]string)
+	if root := byGUID[firefoxTagsRoot]; root != nil {
+		for _, tag := range children[root.id] {
+			for _, e := range children[tag.id] {
+				if e.typ == firefoxTypeBookmark && e.placeID.Valid && tag.title != "" {
+					tags[e.placeID.Int64] = append(tags[e.placeID.Int64], tag.title)
+				}
+			}
+		}
+	}
+
+	w := &firefoxWalker{children: children, tags: tags, keywords: keywords}
+	root := &models.FolderNode{}
+	for _, top := range firefoxRoots {
+		e := byGUID[top.guid]
+		if e == nil || len(children[e.id]) == 0 {
+			continue
+		}
+		if top.name == "" {
+			w.walkChildren(e.id, root)
+			continue
+		}
+		folder := root.AddFolder(top.name)
+		folder.Folder.Toolbar = top.guid == firefoxToolbarRoot
+		folder.Folder.CreatedAt = parsePRTime(e.dateAdded)
+		folder.Folder.UpdatedAt = parsePRTime(e.lastModified)
+		w.walkChildren(e.id, folder)
+	}
+	return root, nil
+}
+
+// readFirefoxEntries returns all bookmarks, folders and separators in their
+// order within the parent folder
+func readFirefoxEntries(db *sql.DB) ([]firefoxEntry, error) {
+	rows, err := db.Query(`
+		SELECT b.id, b.type, b.parent, b.fk, COALESCE(b.title, p.title, ''), COALESCE(p.url, ''),
+			COALESCE(b.guid, ''), b.dateAdded, b.lastModified, p.last_visit_date
+		FROM moz_bookmarks AS b
+		LEFT JOIN moz_places AS p ON p.id = b.fk
+		ORDER BY b.parent, b.position
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []firefoxEntry
+	for rows.Next() {
+		var e firefoxEntry
+		err := rows.Scan(&e.id, &e.typ, &e.parent, &e.placeID, &e.title, &e.url,
+			&e.guid, &e.dateAdded, &e.lastModified, &e.lastVisit)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// readFirefoxKeywords returns the keyword of each place that has one
+func readFirefoxKeywords(db *sql.DB) (map[int64]string, error) {
+	rows, err := db.Query(`SELECT place_id, keyword FROM moz_keywords WHERE place_id IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keywords := make(map[int64]string)
+	for rows.Next() {
+		var placeID int64
+		var keyword string
+		if err := rows.Scan(&placeID, &keyword); err != nil {
+			return nil, err
+		}
+		keywords[placeID] = keyword
+	}
+	return keywords, rows.Err()
+}
+
+// firefoxWalker rebuilds the folder hierarchy below a Firefox root
+type firefoxWalker struct {
+	children map[int64][]*firefoxEntry
+	tags     map[int64][]string
+	keywords map[int64]string
+}
+
+func (w *firefoxWalker) walkChildren(parent int64, folder *models.FolderNode) {
+	for _, e := range w.children[parent] {
+		switch e.typ {
+		case firefoxTypeFolder:
+			name := strings.TrimSpace(e.title)
+			if name == "" {
+				name = "Untitled"
+			}
+			sub := folder.AddFolder(name)
+			sub.Folder.CreatedAt = parsePRTime(e.dateAdded)
+			sub.Folder.UpdatedAt = parsePRTime(e.lastModified)
+			w.walkChildren(e.id, sub)
+		case firefoxTypeBookmark:
+			// place: URLs are saved searches such as "Most Visited"
+			if e.url == "" || strings.HasPrefix(e.url, "place:") {
+				continue
+			}
+			title := strings.TrimSpace(e.title)
+			if title == "" {
+				title = e.url
+			}
+			b := models.Bookmark{
+				Title:         title,
+				URL:           e.url,
+				CreatedAt:     parsePRTime(e.dateAdded),
+				UpdatedAt:     parsePRTime(e.lastModified),
+				LastVisitedAt: parsePRTime(e.lastVisit),
+			}
+			if e.placeID.Valid {
+				b.Tags = w.tags[e.placeID.Int64]
+				b.Keyword = w.keywords[e.placeID.Int64]
+			}
+			folder.Bookmarks = append(folder.Bookmarks, b)
+		}
+	}
+}
+
+// parsePRTime converts a Firefox timestamp (microseconds since the Unix epoch).
+// Returns zero time for NULL or non-positive values.
+func parsePRTime(v sql.NullInt64) time.Time {
+	if !v.Valid || v.Int64 <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(v.Int64)
+}
+
+// copyFile copies the file at src to dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return writeFile(dst, in)
+}
+
+// writeFile creates the file dst with the content read from r
+func writeFile(dst string, r io.Reader) error {
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
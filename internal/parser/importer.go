@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// Importer reads bookmarks in one file format. Parsing has no side effects:
+// the result is an in-memory tree that the caller saves.
+type Importer interface {
+	// Name is the format name, e.g. "html", as accepted by import --format
+	Name() string
+	// Detect reports whether head, the beginning of a file, is in this format
+	Detect(head []byte) bool
+	// Parse reads a whole file and returns its root folder
+	Parse(r io.Reader) (*models.FolderNode, error)
+}
+
+// FileImporter is implemented by importers that read better from the file
+// itself than from a stream, e.g. a database together with its journal
+type FileImporter interface {
+	Importer
+	ParseFile(path string) (*models.FolderNode, error)
+}
+
+// ErrUnknownFormat is returned by DetectFile if no importer recognizes a file
+var ErrUnknownFormat = errors.New("unknown file format")
+
+// detectSize is how much of a file is read for format detection
+const detectSize = 4096
+
+// Registry is a set of importers, looked up by name or by content
+type Registry struct {
+	importers []Importer
+}
+
+// NewRegistry creates a registry. Detection tries the importers in the
+// given order, so more specific formats should come first.
+func NewRegistry(importers ...Importer) *Registry {
+	return &Registry{importers: importers}
+}
+
+// DefaultRegistry returns a registry with all supported formats
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		NewNativeImporter(),
+		NewBukuImporter(),
+		NewFirefoxImporter(),
+		NewChromeImporter(),
+		NewPinboardImporter(),
+		NewDeliciousImporter(),
+		NewOPMLImporter(),
+		NewURLListImporter(),
+		NewCSVImporter(),
+		NewHTMLImporter(),
+	)
+}
+
+// Names returns the names of all formats
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.importers))
+	for i, imp := range r.importers {
+		names[i] = imp.Name()
+	}
+	return names
+}
+
+// Get returns the importer for a format name, or nil if there is none
+func (r *Registry) Get(name string) Importer {
+	for _, imp := range r.importers {
+		if imp.Name() == name {
+			return imp
+		}
+	}
+	return nil
+}
+
+// Detect returns the first importer that recognizes head, or nil
+func (r *Registry) Detect(head []byte) Importer {
+	for _, imp := range r.importers {
+		if imp.Detect(head) {
+			return imp
+		}
+	}
+	return nil
+}
+
+// DetectFile returns the importer for the format of a file
+func (r *Registry) DetectFile(path string) (Importer, error) {
+	head, err := readHead(path)
+	if err != nil {
+		return nil, err
+	}
+	imp := r.Detect(head)
+	if imp == nil {
+		return nil, ErrUnknownFormat
+	}
+	return imp, nil
+}
+
+// ParseFile parses a file with the given importer
+func ParseFile(imp Importer, path string) (*models.FolderNode, error) {
+	if fi, ok := imp.(FileImporter); ok {
+		return fi.ParseFile(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return imp.Parse(f)
+}
+
+// readHead returns the first detectSize bytes of a file (or less if shorter).
+// For an SQLite database it returns the whole first page, which holds the
+// schema that tells databases of different programs apart.
+func readHead(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	head := make([]byte, detectSize)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	if pageSize := sqlitePageSize(head); pageSize > len(head) {
+		page := make([]byte, pageSize)
+		copy(page, head)
+		m, err := io.ReadFull(f, page[len(head):])
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		head = page[:len(head)+m]
+	}
+	return head, nil
+}
+
+// sqlitePageSize returns the page size of the SQLite database starting with
+// head, or 0 if head is not the beginning of one
+func sqlitePageSize(head []byte) int {
+	if !bytes.HasPrefix(head, sqliteHeader) || len(head) < 18 {
+		return 0
+	}
+	// A big-endian 16-bit number at offset 16, where 1 stands for 65536
+	size := int(binary.BigEndian.Uint16(head[16:18]))
+	if size == 1 {
+		size = 65536
+	}
+	return size
+}
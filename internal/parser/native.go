@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"io"
+	"regexp"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+	"github.com/dastanaron/bookmarks/internal/native"
+)
+
+// nativeFormatField matches the format marker near the top of a native JSON file
+var nativeFormatField = regexp.MustCompile(`"format"\s*:\s*"` + regexp.QuoteMeta(native.FormatName) + `"`)
+
+// NativeImporter reads the native JSON format written by "export --format json".
+// It restores every field, including IDs where they are still free.
+type NativeImporter struct{}
+
+// NewNativeImporter creates a new native JSON importer
+func NewNativeImporter() *NativeImporter {
+	return &NativeImporter{}
+}
+
+// Name returns the format name
+func (p *NativeImporter) Name() string {
+	return "json"
+}
+
+// Detect reports whether head is the beginning of a native JSON file
+func (p *NativeImporter) Detect(head []byte) bool {
+	return nativeFormatField.Match(head)
+}
+
+// Parse parses a native JSON file and returns its folder tree
+func (p *NativeImporter) Parse(r io.Reader) (*models.FolderNode, error) {
+	root, err := native.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	root.HasReadLater = true
+	return root, nil
+}
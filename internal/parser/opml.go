@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// opmlDocument is the part of an OPML 1.0 or 2.0 document that holds outlines
+type opmlDocument struct {
+	Outlines []opmlOutline `xml:"body>outline"`
+}
+
+// opmlOutline is an outline of any type: a link, a feed subscription or a
+// plain text node grouping other outlines
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	URL      string        `xml:"url,attr"`
+	HTMLURL  string        `xml:"htmlUrl,attr"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	Note     string        `xml:"_note,attr"`
+	Category string        `xml:"category,attr"`
+	Created  string        `xml:"created,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// OPMLImporter reads OPML outlines, as written by outliners, feed readers
+// and the OPML exporter
+type OPMLImporter struct{}
+
+// NewOPMLImporter creates a new OPML importer
+func NewOPMLImporter() *OPMLImporter {
+	return &OPMLImporter{}
+}
+
+// Name returns the format name
+func (p *OPMLImporter) Name() string {
+	return "opml"
+}
+
+// Detect reports whether head contains an <opml> root element
+func (p *OPMLImporter) Detect(head []byte) bool {
+	head = bytes.TrimLeft(head, " \t\r\n\ufeff")
+	return bytes.HasPrefix(head, []byte("<opml")) ||
+		(bytes.HasPrefix(head, []byte("<?xml")) && bytes.Contains(head, []byte("<opml")))
+}
+
+// Parse parses an OPML document and returns its folder tree. Outlines with
+// a URL become bookmarks (for feed subscriptions, the site's htmlUrl or else
+// the feed's xmlUrl), outlines with children become folders and other
+// outlines are skipped.
+func (p *OPMLImporter) Parse(r io.Reader) (*models.FolderNode, error) {
+	var doc opmlDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid OPML: %w", err)
+	}
+
+	root := &models.FolderNode{}
+	addOPMLOutlines(root, doc.Outlines)
+	return root, nil
+}
+
+// addOPMLOutlines adds outlines and their children to parent
+func addOPMLOutlines(parent *models.FolderNode, outlines []opmlOutline) {
+	for _, o := range outlines {
+		text := strings.TrimSpace(o.Text)
+		if text == "" {
+			text = strings.TrimSpace(o.Title)
+		}
+
+		url := o.URL
+		if url == "" {
+			url = o.HTMLURL
+		}
+		if url == "" {
+			url = o.XMLURL
+		}
+		if url = strings.TrimSpace(url); url != "" {
+			b := models.Bookmark{
+				Title:       text,
+				URL:         url,
+				Description: strings.TrimSpace(o.Note),
+				CreatedAt:   parseOPMLTime(o.Created),
+			}
+			if b.Title == "" {
+				b.Title = url
+			}
+			if o.Category != "" {
+				b.Tags = opmlCategories(o.Category)
+			}
+			parent.Bookmarks = append(parent.Bookmarks, b)
+		}
+
+		// A link with children keeps them in a folder of the same name
+		if len(o.Outlines) > 0 {
+			if text == "" {
+				text = "Untitled"
+			}
+			folder := parent.AddFolder(text)
+			folder.Folder.CreatedAt = parseOPMLTime(o.Created)
+			addOPMLOutlines(folder, o.Outlines)
+		}
+	}
+}
+
+// opmlCategories returns the tags in an OPML category attribute: a
+// comma-separated list of slash-delimited categories such as "/go,/docs".
+// Only the last part of a category is used.
+func opmlCategories(s string) []string {
+	tags := []string{}
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimRight(strings.TrimSpace(c), "/")
+		if i := strings.LastIndex(c, "/"); i >= 0 {
+			c = c[i+1:]
+		}
+		if c = strings.TrimSpace(c); c != "" {
+			tags = append(tags, c)
+		}
+	}
+	return tags
+}
+
+// parseOPMLTime parses an RFC 822 date as used by OPML. Returns zero time
+// for missing or invalid values.
+func parseOPMLTime(val string) time.Time {
+	val = strings.TrimSpace(val)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, time.RFC822Z, time.RFC822} {
+		if t, err := time.Parse(layout, val); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -2,7 +2,9 @@ package parser
 
 import (
 	"io"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dastanaron/bookmarks/internal/models"
 	"github.com/dastanaron/bookmarks/internal/service"
@@ -10,31 +12,48 @@ import (
 	"golang.org/x/net/html"
 )
 
-// Parser parses HTML bookmark files
-type Parser struct {
-	folderService *service.FolderService
+// HTMLImporter reads Netscape bookmark files, the HTML format exported by
+// all major browsers
+type HTMLImporter struct{}
+
+// NewHTMLImporter creates a new HTML importer
+func NewHTMLImporter() *HTMLImporter {
+	return &HTMLImporter{}
 }
 
-// NewParser creates a new parser
-func NewParser(folderService *service.FolderService) *Parser {
-	return &Parser{folderService: folderService}
+// Name returns the format name
+func (p *HTMLImporter) Name() string {
+	return "html"
 }
 
-// folderRec represents a folder in the parsing context
-type folderRec struct {
-	id   int
-	name string
+// Detect reports whether head looks like a Netscape bookmark file or at
+// least like HTML with links
+func (p *HTMLImporter) Detect(head []byte) bool {
+	s := strings.ToLower(string(head))
+	return strings.Contains(s, "<!doctype netscape-bookmark-file") ||
+		strings.Contains(s, "<dl") || strings.Contains(s, "<a ") || strings.Contains(s, "<html")
 }
 
-// ParseBookmarksHTML parses an HTML bookmark file and returns all found bookmarks
-func (p *Parser) ParseBookmarksHTML(r io.Reader) ([]models.Bookmark, error) {
+// Parse parses an HTML bookmark file and returns its folder tree
+func (p *HTMLImporter) Parse(r io.Reader) (*models.FolderNode, error) {
 	doc, err := html.Parse(r)
 	if err != nil {
 		return nil, err
 	}
 
-	bookmarks := make([]models.Bookmark, 0)
-	folderStack := make([]*folderRec, 0)
+	root := &models.FolderNode{}
+	folderStack := make([]*models.FolderNode, 0)
+
+	current := func() *models.FolderNode {
+		if len(folderStack) > 0 {
+			return folderStack[len(folderStack)-1]
+		}
+		return root
+	}
+
+	// The last bookmark added, which a following <DD> describes. It points
+	// into the Bookmarks slice of its folder and is reset on every append.
+	var last *models.Bookmark
 
 	var walk func(*html.Node)
 	walk = func(n *html.Node) {
@@ -43,11 +62,24 @@ func (p *Parser) ParseBookmarksHTML(r io.Reader) ([]models.Bookmark, error) {
 			switch n.Data {
 			case "h3":
 				// Folder header: <H3>Folder Name</H3>
-				p.processFolderNode(n, &folderStack)
+				if folder := p.processFolderNode(n, current()); folder != nil {
+					folderStack = append(folderStack, folder)
+				}
+				last = nil
 			case "a":
 				// Bookmark link: <A HREF="..." ICON="...">Title</A>
-				if bookmark := p.processBookmarkNode(n, folderStack); bookmark != nil {
-					bookmarks = append(bookmarks, *bookmark)
+				last = nil
+				if bookmark := p.processBookmarkNode(n); bookmark != nil {
+					parent := current()
+					parent.Bookmarks = append(parent.Bookmarks, *bookmark)
+					last = &parent.Bookmarks[len(parent.Bookmarks)-1]
+				}
+			case "dd":
+				// Description of the preceding bookmark: <DD>Text. Folders
+				// can have one too, which is ignored.
+				if last != nil {
+					last.Description = ddText(n)
+					last = nil
 				}
 			}
 		}
@@ -58,51 +90,46 @@ func (p *Parser) ParseBookmarksHTML(r io.Reader) ([]models.Bookmark, error) {
 		}
 
 		// After processing children, close folder if this was a DL container
-		if n.Type == html.ElementNode && n.Data == "dl" {
-			p.processFolderClose(&folderStack)
+		if n.Type == html.ElementNode && n.Data == "dl" && len(folderStack) > 0 {
+			folderStack = folderStack[:len(folderStack)-1]
 		}
 	}
 
 	walk(doc)
-	return bookmarks, nil
+	return root, nil
 }
 
-// processFolderNode processes an <H3> node representing a folder
-func (p *Parser) processFolderNode(n *html.Node, folderStack *[]*folderRec) {
+// processFolderNode adds the folder of an <H3> node to parent and returns it,
+// or nil if the folder has no name
+func (p *HTMLImporter) processFolderNode(n *html.Node, parent *models.FolderNode) *models.FolderNode {
 	if n.FirstChild == nil {
-		return
+		return nil
 	}
 
 	folderName := strings.TrimSpace(n.FirstChild.Data)
 	if folderName == "" {
-		return
-	}
-
-	// Determine parent folder ID
-	var parentID *int
-	if len(*folderStack) > 0 {
-		parentID = &(*folderStack)[len(*folderStack)-1].id
+		return nil
 	}
 
-	// Create or find folder in database
-	folder, err := p.folderService.Upsert(folderName, parentID)
-	if err != nil {
-		// Skip folder on error, but continue parsing
-		return
+	folder := parent.AddFolder(folderName)
+	for _, attr := range n.Attr {
+		switch attr.Key {
+		case "personal_toolbar_folder":
+			folder.Folder.Toolbar = strings.EqualFold(attr.Val, "true")
+		case "add_date":
+			folder.Folder.CreatedAt = parseTimestamp(attr.Val)
+		case "last_modified":
+			folder.Folder.UpdatedAt = parseTimestamp(attr.Val)
+		}
 	}
-
-	// Add folder to stack
-	*folderStack = append(*folderStack, &folderRec{
-		id:   folder.ID,
-		name: folder.Name,
-	})
+	return folder
 }
 
 // processBookmarkNode processes an <A> node representing a bookmark
-func (p *Parser) processBookmarkNode(n *html.Node, folderStack []*folderRec) *models.Bookmark {
+func (p *HTMLImporter) processBookmarkNode(n *html.Node) *models.Bookmark {
 	bookmark := &models.Bookmark{}
 
-	// Extract attributes (href, icon)
+	// Extract attributes (href, icon, tags, keyword, dates)
 	for _, attr := range n.Attr {
 		switch attr.Key {
 		case "href":
@@ -110,6 +137,17 @@ func (p *Parser) processBookmarkNode(n *html.Node, folderStack []*folderRec) *mo
 		case "icon":
 			iconVal := attr.Val
 			bookmark.Icon = &iconVal
+		case "tags":
+			// Comma-separated list, as written by Firefox and Pinboard
+			bookmark.Tags = service.ParseTags(attr.Val)
+		case "shortcuturl":
+			bookmark.Keyword = strings.TrimSpace(attr.Val)
+		case "add_date":
+			bookmark.CreatedAt = parseTimestamp(attr.Val)
+		case "last_modified":
+			bookmark.UpdatedAt = parseTimestamp(attr.Val)
+		case "last_visit":
+			bookmark.LastVisitedAt = parseTimestamp(attr.Val)
 		}
 	}
 
@@ -123,18 +161,35 @@ func (p *Parser) processBookmarkNode(n *html.Node, folderStack []*folderRec) *mo
 		return nil
 	}
 
-	// Assign folder ID from current folder stack
-	if len(folderStack) > 0 {
-		currentFolderID := folderStack[len(folderStack)-1].id
-		bookmark.FolderID = &currentFolderID
-	}
-
 	return bookmark
 }
 
-// processFolderClose handles closing of a <DL> container (end of folder)
-func (p *Parser) processFolderClose(folderStack *[]*folderRec) {
-	if len(*folderStack) > 0 {
-		*folderStack = (*folderStack)[:len(*folderStack)-1]
+// ddText returns the text of a <DD> node. The parser puts the <DL> of a
+// folder inside the <DD> describing it, so only direct text children count.
+func ddText(n *html.Node) string {
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode {
+			sb.WriteString(child.Data)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// parseTimestamp parses a Netscape date attribute (unix seconds).
+// Some exporters write milliseconds or microseconds, which are detected by
+// magnitude. Returns zero time for missing or invalid values.
+func parseTimestamp(val string) time.Time {
+	n, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	}
+	switch {
+	case n > 1e14: // microseconds
+		return time.UnixMicro(n)
+	case n > 1e11: // milliseconds
+		return time.UnixMilli(n)
+	default:
+		return time.Unix(n, 0)
 	}
 }
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// post is a bookmark as exported by Pinboard, Delicious and buku, which
+// have no folders, only tags
+type post struct {
+	URL      string
+	Title    string
+	Extended string // long description
+	Tags     []string
+	Time     string // RFC 3339, empty if unknown
+	ToRead   string // "yes" or "no"
+}
+
+// pinboardPost is an entry of a Pinboard JSON export
+// (https://api.pinboard.in/v1/posts/all?format=json)
+type pinboardPost struct {
+	Href        string `json:"href"`
+	Description string `json:"description"`
+	Extended    string `json:"extended"`
+	Tags        string `json:"tags"`
+	Time        string `json:"time"`
+	ToRead      string `json:"toread"`
+}
+
+// PinboardImporter reads the JSON export of Pinboard
+type PinboardImporter struct {
+	// TagFolders puts each bookmark into a folder named after its first tag
+	// instead of keeping all tags as tags
+	TagFolders bool
+}
+
+// NewPinboardImporter creates a new Pinboard JSON importer
+func NewPinboardImporter() *PinboardImporter {
+	return &PinboardImporter{}
+}
+
+// Name returns the format name
+func (p *PinboardImporter) Name() string {
+	return "pinboard"
+}
+
+// Detect reports whether head looks like a Pinboard JSON export: an array
+// of objects with "href" and Pinboard's "hash" or "toread" fields
+func (p *PinboardImporter) Detect(head []byte) bool {
+	head = bytes.TrimLeft(head, " \t\r\n\ufeff")
+	return bytes.HasPrefix(head, []byte("[")) && bytes.Contains(head, []byte(`"href"`)) &&
+		(bytes.Contains(head, []byte(`"hash"`)) || bytes.Contains(head, []byte(`"toread"`)))
+}
+
+// Parse parses a Pinboard JSON export and returns its folder tree
+func (p *PinboardImporter) Parse(r io.Reader) (*models.FolderNode, error) {
+	var entries []pinboardPost
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("invalid Pinboard JSON: %w", err)
+	}
+
+	posts := make([]post, len(entries))
+	for i, e := range entries {
+		posts[i] = post{
+			URL:      e.Href,
+			Title:    e.Description,
+			Extended: e.Extended,
+			Tags:     strings.Fields(e.Tags),
+			Time:     e.Time,
+			ToRead:   e.ToRead,
+		}
+	}
+	return postsTree(posts, p.TagFolders), nil
+}
+
+// postsTree returns the folder tree of posts. Without tagFolders all
+// bookmarks are in the root; with it, each one is in a folder named after
+// its first tag and keeps the other tags.
+func postsTree(posts []post, tagFolders bool) *models.FolderNode {
+	root := &models.FolderNode{HasReadLater: true}
+	for _, ps := range posts {
+		b := models.Bookmark{
+			URL:         strings.TrimSpace(ps.URL),
+			Title:       strings.TrimSpace(ps.Title),
+			Description: strings.TrimSpace(ps.Extended),
+			Tags:        ps.Tags,
+			ReadLater:   ps.ToRead == "yes",
+		}
+		if b.URL == "" {
+			continue
+		}
+		if b.Title == "" {
+			b.Title = b.URL
+		}
+		if b.Tags == nil {
+			b.Tags = []string{}
+		}
+		if t, err := time.Parse(time.RFC3339, strings.TrimSpace(ps.Time)); err == nil {
+			b.CreatedAt = t
+		}
+
+		folder := root
+		if tagFolders && len(b.Tags) > 0 {
+			folder = root.Subfolder(b.Tags[0])
+			b.Tags = b.Tags[1:]
+		}
+		folder.Bookmarks = append(folder.Bookmarks, b)
+	}
+	return root
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// URLListImporter reads plain text with one URL per line, optionally
+// followed by a tab and a title, such as the output of grep over notes.
+// Blank lines and lines starting with # are ignored.
+type URLListImporter struct{}
+
+// NewURLListImporter creates a new URL list importer
+func NewURLListImporter() *URLListImporter {
+	return &URLListImporter{}
+}
+
+// Name returns the format name
+func (p *URLListImporter) Name() string {
+	return "urls"
+}
+
+// Detect reports whether every complete line of head is a URL list line.
+// Lines that all contain a comma are more likely CSV without a header.
+func (p *URLListImporter) Detect(head []byte) bool {
+	head = bytes.TrimPrefix(head, []byte("\ufeff"))
+	lines := strings.Split(string(head), "\n")
+	if len(head) == detectSize && len(lines) > 1 {
+		lines = lines[:len(lines)-1] // cut off in the middle
+	}
+
+	urls, commas := 0, 0
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		url, _, ok := urlListLine(line)
+		if !ok {
+			return false
+		}
+		urls++
+		if strings.Contains(url, ",") {
+			commas++
+		}
+	}
+	return urls > 0 && commas < urls
+}
+
+// Parse parses a URL list and returns the bookmarks, all in the root folder.
+// Bookmarks without a title are left untitled, so an import doesn't replace
+// the titles of existing bookmarks, and lines that are not URLs are skipped.
+func (p *URLListImporter) Parse(r io.Reader) (*models.FolderNode, error) {
+	root := &models.FolderNode{BareURLs: true}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for first := true; scanner.Scan(); first = false {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		url, title, ok := urlListLine(line)
+		if !ok {
+			continue
+		}
+		root.Bookmarks = append(root.Bookmarks, models.Bookmark{Title: title, URL: url})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// urlListLine splits a trimmed line into its URL and title, and reports
+// whether it starts with a URL without spaces
+func urlListLine(line string) (url, title string, ok bool) {
+	url, title, _ = strings.Cut(line, "\t")
+	url = strings.TrimSpace(url)
+	if strings.ContainsAny(url, " \t") || !looksLikeURL(url) {
+		return "", "", false
+	}
+	return url, strings.TrimSpace(title), true
+}
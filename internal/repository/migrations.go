@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrSchemaTooNew is returned when a database was migrated by a newer binary
+// than the one trying to open it
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary supports")
+
+// migration is a single numbered schema change
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+// migrations lists every schema change in the order it must be applied.
+// Never edit, renumber or remove an entry that has been released: databases
+// are shared between machines running different builds, so append a new
+// migration instead.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create folders and bookmarks tables",
+		// IF NOT EXISTS keeps this safe for databases created before
+		// schema versioning was introduced
+		up: execSQL(`
+			CREATE TABLE IF NOT EXISTS folders (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL,
+				parent_id INTEGER,
+				FOREIGN KEY(parent_id) REFERENCES folders(id)
+			);
+
+			CREATE TABLE IF NOT EXISTS bookmarks (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				title TEXT NOT NULL,
+				url TEXT NOT NULL,
+				description TEXT,
+				folder_id INTEGER,
+				FOREIGN KEY(folder_id) REFERENCES folders(id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_bookmarks_folder ON bookmarks(folder_id);
+			CREATE INDEX IF NOT EXISTS idx_folders_parent ON folders(parent_id);
+		`),
+	},
+	{
+		version: 2,
+		name:    "add bookmarks.icon column",
+		up:      addColumn("bookmarks", "icon", "TEXT"),
+	},
+	{
+		version: 3,
+		name:    "create tags and bookmark_tags tables",
+		up: execSQL(`
+			CREATE TABLE tags (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE COLLATE NOCASE
+			);
+
+			CREATE TABLE bookmark_tags (
+				bookmark_id INTEGER NOT NULL,
+				tag_id INTEGER NOT NULL,
+				PRIMARY KEY (bookmark_id, tag_id),
+				FOREIGN KEY(bookmark_id) REFERENCES bookmarks(id) ON DELETE CASCADE,
+				FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX idx_bookmark_tags_tag ON bookmark_tags(tag_id);
+		`),
+	},
+	{
+		version: 4,
+		name:    "add created/updated/last visited timestamps",
+		// Unix seconds; NULL for rows created before timestamps were tracked
+		up: execSQL(`
+			ALTER TABLE bookmarks ADD COLUMN created_at INTEGER;
+			ALTER TABLE bookmarks ADD COLUMN updated_at INTEGER;
+			ALTER TABLE bookmarks ADD COLUMN last_visited_at INTEGER;
+			ALTER TABLE folders ADD COLUMN created_at INTEGER;
+			ALTER TABLE folders ADD COLUMN updated_at INTEGER;
+		`),
+	},
+	{
+		version: 5,
+		name:    "add deleted_at for trash",
+		// Unix seconds; NULL for rows that are not in the trash
+		up: execSQL(`
+			ALTER TABLE bookmarks ADD COLUMN deleted_at INTEGER;
+			ALTER TABLE folders ADD COLUMN deleted_at INTEGER;
+			CREATE INDEX idx_bookmarks_deleted ON bookmarks(deleted_at);
+			CREATE INDEX idx_folders_deleted ON folders(deleted_at);
+		`),
+	},
+	{
+		version: 6,
+		name:    "repair dangling folder references",
+		// Foreign keys were not enforced before this version, so deleted
+		// folders could leave subfolders and bookmarks pointing nowhere
+		// (hidden from the folder tree). Move them to the root so they
+		// show up again and pass the foreign key checks from now on.
+		up: execSQL(`
+			UPDATE folders SET parent_id = NULL
+			WHERE parent_id = 0 OR parent_id = id OR parent_id NOT IN (SELECT id FROM folders);
+
+			UPDATE bookmarks SET folder_id = NULL
+			WHERE folder_id = 0 OR folder_id NOT IN (SELECT id FROM folders);
+
+			DELETE FROM bookmark_tags
+			WHERE bookmark_id NOT IN (SELECT id FROM bookmarks) OR tag_id NOT IN (SELECT id FROM tags);
+		`),
+	},
+	{
+		version: 7,
+		name:    "add bookmarks.keyword column",
+		// Browser keyword (e.g. "w" for a Wikipedia search); NULL if none
+		up: addColumn("bookmarks", "keyword", "TEXT"),
+	},
+	{
+		version: 8,
+		name:    "add folders.toolbar column",
+		// 1 for the folder browsers show as the bookmarks toolbar
+		// (PERSONAL_TOOLBAR_FOLDER in Netscape files)
+		up: addColumn("folders", "toolbar", "INTEGER NOT NULL DEFAULT 0"),
+	},
+	{
+		version: 9,
+		name:    "add bookmarks.read_later column",
+		// 1 for bookmarks saved to read later (Pinboard's "toread")
+		up: addColumn("bookmarks", "read_later", "INTEGER NOT NULL DEFAULT 0"),
+	},
+}
+
+// MigrationStatus describes a single migration as seen by a database
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt string // empty if not applied
+	Unknown   bool   // applied by a newer binary, not known to this one
+}
+
+// SchemaStatus describes the schema version of a database
+type SchemaStatus struct {
+	CurrentVersion int
+	LatestVersion  int
+	Migrations     []MigrationStatus
+}
+
+// latestVersion returns the highest schema version known to this binary
+func latestVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].version
+}
+
+// migrate brings the database schema up to the latest known version.
+// Each migration runs in its own transaction together with its
+// schema_version record, so a failed migration leaves the database at the
+// previous version.
+func migrate(db *sql.DB) error {
+	if err := ensureVersionTable(db); err != nil {
+		return err
+	}
+
+	current, err := currentVersion(db)
+	if err != nil {
+		return err
+	}
+	if current > latestVersion() {
+		return fmt.Errorf("%w: database is at version %d, this binary supports up to %d",
+			ErrSchemaTooNew, current, latestVersion())
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		err := withTx(db, func(tx *sql.Tx) error {
+			// Another process may have applied it since we checked
+			var applied int
+			if err := tx.QueryRow(`SELECT COUNT(*) FROM schema_version WHERE version = ?`, m.version).Scan(&applied); err != nil {
+				return err
+			}
+			if applied > 0 {
+				return nil
+			}
+			if err := m.up(tx); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`INSERT INTO schema_version(version, name) VALUES (?, ?)`, m.version, m.name)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+// ReadSchemaStatus reports which migrations have been applied to the
+// database at dbPath without modifying it
+func ReadSchemaStatus(dbPath string) (*SchemaStatus, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	status := &SchemaStatus{LatestVersion: latestVersion()}
+
+	var hasTable int
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'
+	`).Scan(&hasTable)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]MigrationStatus)
+	if hasTable > 0 {
+		rows, err := db.Query(`SELECT version, name, applied_at FROM schema_version ORDER BY version`)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var s MigrationStatus
+			if err := rows.Scan(&s.Version, &s.Name, &s.AppliedAt); err != nil {
+				return nil, err
+			}
+			s.Applied = true
+			applied[s.Version] = s
+			if s.Version > status.CurrentVersion {
+				status.CurrentVersion = s.Version
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, m := range migrations {
+		s, ok := applied[m.version]
+		if !ok {
+			s = MigrationStatus{Version: m.version, Name: m.name}
+		}
+		delete(applied, m.version)
+		status.Migrations = append(status.Migrations, s)
+	}
+
+	// Anything left was applied by a newer binary
+	unknown := make([]int, 0, len(applied))
+	for v := range applied {
+		unknown = append(unknown, v)
+	}
+	sort.Ints(unknown)
+	for _, v := range unknown {
+		s := applied[v]
+		s.Unknown = true
+		status.Migrations = append(status.Migrations, s)
+	}
+
+	return status, nil
+}
+
+func ensureVersionTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	return err
+}
+
+func currentVersion(db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	return version, err
+}
+
+// execSQL returns a migration step that executes the given statements
+func execSQL(query string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(query)
+		return err
+	}
+}
+
+// addColumn returns a migration step that adds a column if it doesn't exist.
+// SQLite doesn't support IF NOT EXISTS for ALTER TABLE ADD COLUMN,
+// so we check if the column exists first.
+func addColumn(table, column, definition string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		var count int
+		err := tx.QueryRow(
+			`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`,
+			table, column,
+		).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		_, err = tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+		return err
+	}
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// baselineSchema is the schema created by versions before schema versioning
+const baselineSchema = `
+	CREATE TABLE IF NOT EXISTS folders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		parent_id INTEGER,
+		FOREIGN KEY(parent_id) REFERENCES folders(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS bookmarks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		title TEXT NOT NULL,
+		url TEXT NOT NULL,
+		description TEXT,
+		icon TEXT,
+		folder_id INTEGER,
+		FOREIGN KEY(folder_id) REFERENCES folders(id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_bookmarks_folder ON bookmarks(folder_id);
+	CREATE INDEX IF NOT EXISTS idx_folders_parent ON folders(parent_id);
+`
+
+// createDB creates a database file with the given statements, without
+// running the migrations
+func createDB(t *testing.T, statements string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bookmarks.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if statements != "" {
+		if _, err := db.Exec(statements); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+// openRepo opens a repository, migrating the database
+func openRepo(t *testing.T, path string) *SQLiteRepository {
+	t.Helper()
+	repo, err := NewSQLiteRepository(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+// assertUpToDate checks that every migration is recorded as applied
+func assertUpToDate(t *testing.T, path string) {
+	t.Helper()
+	status, err := ReadSchemaStatus(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.CurrentVersion != latestVersion() {
+		t.Errorf("schema version = %d, want %d", status.CurrentVersion, latestVersion())
+	}
+	for _, m := range status.Migrations {
+		if !m.Applied || m.Unknown {
+			t.Errorf("migration %d (%s): applied = %v, unknown = %v", m.Version, m.Name, m.Applied, m.Unknown)
+		}
+	}
+}
+
+func TestMigrateEmptyDB(t *testing.T) {
+	path := createDB(t, "")
+	repo := openRepo(t, path)
+	assertUpToDate(t, path)
+
+	// The latest columns are usable
+	folder, err := repo.Folders().Create("Reading", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &models.Bookmark{
+		Title:     "Wikipedia",
+		URL:       "https://en.wikipedia.org/",
+		Keyword:   "w",
+		ReadLater: true,
+		FolderID:  &folder.ID,
+	}
+	if err := repo.Bookmarks().Create(b); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	list, err := repo.Bookmarks().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Keyword != "w" || !list[0].ReadLater {
+		t.Errorf("List = %+v, want the created bookmark with its keyword and read-later flag", list)
+	}
+}
+
+func TestMigrateBaselineDB(t *testing.T) {
+	path := createDB(t, baselineSchema+`
+		INSERT INTO folders (id, name, parent_id) VALUES
+			(1, 'Work', NULL),
+			(2, 'Projects', 1),
+			(3, 'Orphan', 42),
+			(4, 'Self', 4),
+			(5, 'Zero', 0);
+		INSERT INTO bookmarks (id, title, url, description, icon, folder_id) VALUES
+			(1, 'Go', 'https://go.dev/', 'The Go site', 'aWNvbg==', 2),
+			(2, 'Lost', 'https://lost.example/', NULL, NULL, 99),
+			(3, 'Root', 'https://root.example/', NULL, NULL, NULL),
+			(4, 'Zero', 'https://zero.example/', NULL, NULL, 0);
+	`)
+	repo := openRepo(t, path)
+	assertUpToDate(t, path)
+
+	// Existing rows survive, dangling references are moved to the root
+	wantFolderParents := map[int]*int{1: nil, 2: intPtr(1), 3: nil, 4: nil, 5: nil}
+	folders, err := repo.Folders().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(folders) != len(wantFolderParents) {
+		t.Fatalf("got %d folders, want %d", len(folders), len(wantFolderParents))
+	}
+	for _, f := range folders {
+		if want := wantFolderParents[f.ID]; !sameIntPtr(f.ParentID, want) {
+			t.Errorf("folder %d (%s): parent = %v, want %v", f.ID, f.Name, fmtIntPtr(f.ParentID), fmtIntPtr(want))
+		}
+	}
+
+	wantBookmarkFolders := map[int]*int{1: intPtr(2), 2: nil, 3: nil, 4: nil}
+	bookmarks, err := repo.Bookmarks().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bookmarks) != len(wantBookmarkFolders) {
+		t.Fatalf("got %d bookmarks, want %d", len(bookmarks), len(wantBookmarkFolders))
+	}
+	for _, b := range bookmarks {
+		if want := wantBookmarkFolders[b.ID]; !sameIntPtr(b.FolderID, want) {
+			t.Errorf("bookmark %d (%s): folder = %v, want %v", b.ID, b.Title, fmtIntPtr(b.FolderID), fmtIntPtr(want))
+		}
+	}
+
+	goSite, err := repo.Bookmarks().GetByID(1)
+	if err != nil || goSite == nil {
+		t.Fatalf("GetByID(1) = %v, %v", goSite, err)
+	}
+	if goSite.Description != "The Go site" || goSite.Icon == nil || *goSite.Icon != "aWNvbg==" {
+		t.Errorf("bookmark 1 lost data: %+v", goSite)
+	}
+	if !goSite.CreatedAt.IsZero() || goSite.Keyword != "" || goSite.ReadLater {
+		t.Errorf("bookmark 1 has values for columns added later: %+v", goSite)
+	}
+
+	// Writes pass the foreign key checks enforced from now on
+	goSite.Title = "Go!"
+	if err := repo.Bookmarks().Update(goSite); err != nil {
+		t.Errorf("Update after migration: %v", err)
+	}
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	path := createDB(t, baselineSchema)
+	repo := openRepo(t, path)
+	repo.Close()
+
+	// Opening an up-to-date database runs no migration again
+	openRepo(t, path)
+	status, err := ReadSchemaStatus(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Migrations) != len(migrations) {
+		t.Errorf("got %d migration records, want %d", len(status.Migrations), len(migrations))
+	}
+	assertUpToDate(t, path)
+}
+
+func TestMigrateSchemaTooNew(t *testing.T) {
+	path := createDB(t, "")
+	openRepo(t, path).Close()
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO schema_version(version, name) VALUES (?, 'from the future')`, latestVersion()+1); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	if _, err := NewSQLiteRepository(path); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("NewSQLiteRepository error = %v, want ErrSchemaTooNew", err)
+	}
+	status, err := ReadSchemaStatus(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := status.Migrations[len(status.Migrations)-1]
+	if !last.Unknown || last.Version != latestVersion()+1 {
+		t.Errorf("last migration = %+v, want the unknown newer one", last)
+	}
+}
+
+func TestReadSchemaStatusPending(t *testing.T) {
+	path := createDB(t, baselineSchema)
+
+	status, err := ReadSchemaStatus(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.CurrentVersion != 0 {
+		t.Errorf("schema version = %d, want 0", status.CurrentVersion)
+	}
+	for _, m := range status.Migrations {
+		if m.Applied {
+			t.Errorf("migration %d reported as applied before migrating", m.Version)
+		}
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func sameIntPtr(a, b *int) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func fmtIntPtr(p *int) interface{} {
+	if p == nil {
+		return "NULL"
+	}
+	return *p
+}
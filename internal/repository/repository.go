@@ -1,18 +1,48 @@
 package repository
 
-import "github.com/dastanaron/bookmarks/internal/models"
+import (
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
 
 // BookmarkRepository defines operations for bookmarks
 type BookmarkRepository interface {
 	List() ([]models.Bookmark, error)
 	GetByID(id int) (*models.Bookmark, error)
+	// GetByURL returns the oldest bookmark with the URL, or nil if there is none
 	GetByURL(url string) (*models.Bookmark, error)
+	// ListByURL returns all bookmarks with the URL, oldest first
+	ListByURL(url string) ([]models.Bookmark, error)
 	Create(b *models.Bookmark) error
 	Update(b *models.Bookmark) error
+	// Replace writes all fields of the bookmark b.ID, like Update, but sets
+	// UpdatedAt to the current time only if b leaves it zero
+	Replace(b *models.Bookmark) error
 	// Upsert creates a new bookmark if URL doesn't exist, otherwise updates the existing one.
 	// Returns true if created, false if updated.
 	Upsert(b *models.Bookmark) (bool, error)
+	// Delete moves a bookmark to the trash
 	Delete(id int) error
+	// MarkVisited sets the last visit time of a bookmark to the current time
+	MarkVisited(id int) error
+	// Search returns bookmarks matching query, best matches first.
+	// folderID limits results to one folder (nil = all), limit 0 means no limit.
+	Search(query string, folderID *int, limit, offset int) ([]models.Bookmark, error)
+
+	// AddTags attaches tags to a bookmark, creating tags that don't exist yet
+	AddTags(bookmarkID int, tags []string) error
+	// RemoveTags detaches tags from a bookmark. Tags left without bookmarks are deleted.
+	RemoveTags(bookmarkID int, tags []string) error
+	// ListTags returns all tags with the number of bookmarks using each
+	ListTags() ([]models.Tag, error)
+	// RenameTag renames a tag. Renaming onto an existing tag is an error, use MergeTags instead.
+	RenameTag(oldName, newName string) error
+	// MergeTags moves all bookmarks from the source tags to target and deletes the sources
+	MergeTags(sources []string, target string) error
+	// ListByTags returns bookmarks having all of the tags if matchAll is true,
+	// or any of them otherwise
+	ListByTags(tags []string, matchAll bool) ([]models.Bookmark, error)
 }
 
 // FolderRepository defines operations for folders
@@ -20,17 +50,47 @@ type FolderRepository interface {
 	List() ([]models.Folder, error)
 	GetByID(id int) (*models.Folder, error)
 	Create(name string, parentID *int) (*models.Folder, error)
+	// Insert creates a folder with the ID (if non-zero) and timestamps of f
+	Insert(f *models.Folder) error
 	Update(f *models.Folder) error
-	Delete(id int) error
+	// Delete moves a folder to the trash, handling its contents according to policy
+	Delete(id int, policy models.FolderDeletePolicy) error
+	// CountContents returns the number of bookmarks and subfolders in a folder,
+	// including nested ones
+	CountContents(id int) (bookmarks int, folders int, err error)
+	// Find returns the folder with the given name and parent, or nil if there is none
+	Find(name string, parentID *int) (*models.Folder, error)
 	Upsert(name string, parentID *int) (*models.Folder, error)
 	// GetFolderContent returns all items (bookmarks and subfolders) in a folder
 	// If folderID is nil, returns all root items (bookmarks without folder and root folders)
 	GetFolderContent(folderID *int) ([]models.Item, error)
 }
 
+// TrashRepository defines operations on deleted bookmarks and folders.
+// Other repositories exclude trashed items from their results.
+type TrashRepository interface {
+	// List returns trashed items. Bookmarks and subfolders trashed together
+	// with their folder are represented by that folder.
+	List() ([]models.Item, error)
+	// Restore takes an item out of the trash into its original folder,
+	// restoring trashed parent folders as needed
+	Restore(itemType models.ItemType, id int) error
+	// Purge permanently deletes a trashed item, a folder with all its contents
+	Purge(itemType models.ItemType, id int) error
+	// Empty permanently deletes items trashed before the given time
+	Empty(before time.Time) (bookmarks int, folders int, err error)
+}
+
 // Repository combines all repositories
 type Repository interface {
 	Bookmarks() BookmarkRepository
 	Folders() FolderRepository
+	Trash() TrashRepository
+	// FullTextSearch reports whether Search uses the full-text index, which
+	// needs a build with the sqlite_fts5 tag, instead of scanning every bookmark
+	FullTextSearch() bool
+	// Transaction runs fn with a repository whose changes are committed
+	// together if fn returns nil and rolled back otherwise
+	Transaction(fn func(repo Repository) error) error
 	Close() error
 }
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"unicode"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// searchTriggers keep bookmarks_fts in sync with the bookmarks table
+var searchTriggers = map[string]string{
+	"bookmarks_fts_ai": `
+		CREATE TRIGGER bookmarks_fts_ai AFTER INSERT ON bookmarks BEGIN
+			INSERT INTO bookmarks_fts(rowid, title, url, description)
+			VALUES (new.id, new.title, new.url, COALESCE(new.description, ''));
+		END`,
+	"bookmarks_fts_ad": `
+		CREATE TRIGGER bookmarks_fts_ad AFTER DELETE ON bookmarks BEGIN
+			INSERT INTO bookmarks_fts(bookmarks_fts, rowid, title, url, description)
+			VALUES ('delete', old.id, old.title, old.url, COALESCE(old.description, ''));
+		END`,
+	"bookmarks_fts_au": `
+		CREATE TRIGGER bookmarks_fts_au AFTER UPDATE ON bookmarks BEGIN
+			INSERT INTO bookmarks_fts(bookmarks_fts, rowid, title, url, description)
+			VALUES ('delete', old.id, old.title, old.url, COALESCE(old.description, ''));
+			INSERT INTO bookmarks_fts(rowid, title, url, description)
+			VALUES (new.id, new.title, new.url, COALESCE(new.description, ''));
+		END`,
+}
+
+// syncSearchIndex sets up the FTS5 index over bookmarks and reports whether
+// it can be used.
+//
+// FTS5 is only compiled into go-sqlite3 with the sqlite_fts5 build tag, and
+// the same database file may be opened by builds with and without it. So the
+// index is not a versioned migration: a build without FTS5 drops the sync
+// triggers (otherwise every write would fail with "no such module"), and a
+// build with FTS5 recreates them and rebuilds the index from scratch.
+func syncSearchIndex(db *sql.DB) (bool, error) {
+	var enabled int
+	if err := db.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&enabled); err != nil {
+		return false, err
+	}
+
+	return enabled == 1, withTx(db, func(tx *sql.Tx) error {
+		if enabled != 1 {
+			for name := range searchTriggers {
+				if _, err := tx.Exec(`DROP TRIGGER IF EXISTS ` + name); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		var present int
+		err := tx.QueryRow(`
+			SELECT COUNT(*) FROM sqlite_master
+			WHERE type = 'trigger' AND name IN ('bookmarks_fts_ai', 'bookmarks_fts_ad', 'bookmarks_fts_au')
+		`).Scan(&present)
+		if err != nil {
+			return err
+		}
+		if present == len(searchTriggers) {
+			return nil
+		}
+
+		_, err = tx.Exec(`
+			CREATE VIRTUAL TABLE IF NOT EXISTS bookmarks_fts USING fts5(
+				title, url, description,
+				content='bookmarks', content_rowid='id',
+				tokenize='unicode61 remove_diacritics 2'
+			)
+		`)
+		if err != nil {
+			return err
+		}
+		for name, trigger := range searchTriggers {
+			if _, err := tx.Exec(`DROP TRIGGER IF EXISTS ` + name); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(trigger); err != nil {
+				return err
+			}
+		}
+		// Writes made while the triggers were missing are not indexed
+		_, err = tx.Exec(`INSERT INTO bookmarks_fts(bookmarks_fts) VALUES ('rebuild')`)
+		return err
+	})
+}
+
+// Search returns bookmarks matching query, best matches first.
+// Every word must match a title, URL or description, either as a word prefix
+// (full-text index) or as a substring (fallback without FTS5); "quoted text"
+// matches a phrase. An empty query returns all bookmarks. folderID limits
+// results to a single folder, and a limit of 0 means no limit.
+func (r *bookmarkRepo) Search(query string, folderID *int, limit, offset int) ([]models.Bookmark, error) {
+	terms := parseSearchQuery(query)
+	if len(terms) > 0 && !r.fts {
+		return r.searchSubstring(terms, folderID, limit, offset)
+	}
+
+	var where []string
+	var args []interface{}
+	from := bookmarkSelect
+	order := `b.title`
+
+	if len(terms) > 0 {
+		from += ` JOIN bookmarks_fts ON bookmarks_fts.rowid = b.id `
+		where = append(where, `bookmarks_fts MATCH ?`)
+		args = append(args, ftsQuery(terms))
+		// Title matches weigh more than URL matches, URL more than description
+		order = `bm25(bookmarks_fts, 10.0, 5.0, 1.0), b.title`
+	}
+	if folderID != nil {
+		where = append(where, `b.folder_id = ?`)
+		args = append(args, *folderID)
+	}
+	where = append(where, `b.url <> ''`, `b.deleted_at IS NULL`)
+
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Query(from+`
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY `+order+`
+		LIMIT ? OFFSET ?
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookmarks(r.db, rows)
+}
+
+// searchSubstring is the Search fallback for builds without FTS5.
+// Matching is done in Go because SQLite's LIKE only folds ASCII case.
+func (r *bookmarkRepo) searchSubstring(terms []searchTerm, folderID *int, limit, offset int) ([]models.Bookmark, error) {
+	query := bookmarkSelect + `WHERE b.url <> '' AND b.deleted_at IS NULL`
+	var args []interface{}
+	if folderID != nil {
+		query += ` AND b.folder_id = ?`
+		args = append(args, *folderID)
+	}
+
+	rows, err := r.db.Query(query+` ORDER BY b.title`, args...)
+	if err != nil {
+		return nil, err
+	}
+	all, err := scanBookmarks(r.db, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []models.Bookmark
+	for _, b := range all {
+		title := strings.ToLower(b.Title)
+		url := strings.ToLower(b.URL)
+		desc := strings.ToLower(b.Description)
+
+		ok := true
+		for _, t := range terms {
+			text := strings.ToLower(t.text)
+			if !strings.Contains(title, text) && !strings.Contains(url, text) && !strings.Contains(desc, text) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, b)
+		}
+	}
+
+	if offset >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[offset:]
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+// searchTerm is a single word or quoted phrase of a search query
+type searchTerm struct {
+	text   string
+	phrase bool
+}
+
+// parseSearchQuery splits a query into words and "quoted phrases".
+// Terms without any letters or digits are dropped, they can't match anything
+// in the full-text index.
+func parseSearchQuery(query string) []searchTerm {
+	var terms []searchTerm
+	add := func(text string, phrase bool) {
+		if strings.IndexFunc(text, func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.IsDigit(r)
+		}) >= 0 {
+			terms = append(terms, searchTerm{text: text, phrase: phrase})
+		}
+	}
+
+	for query != "" {
+		query = strings.TrimLeftFunc(query, unicode.IsSpace)
+		if query == "" {
+			break
+		}
+		if query[0] == '"' {
+			end := strings.IndexByte(query[1:], '"')
+			if end < 0 {
+				// Unterminated quote: the rest is the phrase
+				add(query[1:], true)
+				break
+			}
+			add(query[1:end+1], true)
+			query = query[end+2:]
+			continue
+		}
+		end := strings.IndexFunc(query, unicode.IsSpace)
+		if end < 0 {
+			end = len(query)
+		}
+		add(query[:end], false)
+		query = query[end:]
+	}
+	return terms
+}
+
+// ftsQuery builds an FTS5 MATCH expression from search terms.
+// Every term is quoted so user input can't inject FTS5 syntax;
+// words become prefix queries so results update while typing.
+func ftsQuery(terms []searchTerm) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		quoted := `"` + strings.ReplaceAll(t.text, `"`, `""`) + `"`
+		if !t.phrase {
+			quoted += "*"
+		}
+		parts[i] = quoted
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// TestSearch runs with and without the sqlite_fts5 build tag; each case
+// lists the titles found with the full-text index and by the substring fallback
+func TestSearch(t *testing.T) {
+	repo := openRepo(t, createDB(t, ""))
+	for _, b := range []models.Bookmark{
+		{Title: "Misc", URL: "https://c.example/", Description: "All about gophers"},
+		{Title: "Notes", URL: "https://gopher.example/"},
+		{Title: "Gopher gallery", URL: "https://a.example/"},
+		{Title: "Tools", URL: "https://d.example/", Description: "The go tool chain"},
+		{Title: "Chain", URL: "https://e.example/", Description: "A tool for go"},
+		{Title: "Trashed gopher", URL: "https://f.example/"},
+	} {
+		b := b
+		if err := repo.Bookmarks().Create(&b); err != nil {
+			t.Fatal(err)
+		}
+		if b.Title == "Trashed gopher" {
+			if err := repo.Bookmarks().Delete(b.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		fts       []string
+		substring []string
+	}{
+		{
+			// Title matches rank above URL matches, URL above description
+			name:      "ranking",
+			query:     "GOPHER",
+			fts:       []string{"Gopher gallery", "Notes", "Misc"},
+			substring: []string{"Gopher gallery", "Misc", "Notes"},
+		},
+		{
+			// "tool" is a prefix of the title Tools
+			name:      "words match anywhere",
+			query:     "go tool",
+			fts:       []string{"Tools", "Chain"},
+			substring: []string{"Chain", "Tools"},
+		},
+		{
+			name:      "quoted phrase",
+			query:     `"go tool"`,
+			fts:       []string{"Tools"},
+			substring: []string{"Tools"},
+		},
+		{
+			name:      "word prefix",
+			query:     "gall",
+			fts:       []string{"Gopher gallery"},
+			substring: []string{"Gopher gallery"},
+		},
+		{
+			// Only the fallback matches inside words
+			name:      "inside a word",
+			query:     "allery",
+			substring: []string{"Gopher gallery"},
+		},
+		{
+			name:      "no letters or digits",
+			query:     `"" ***`,
+			fts:       []string{"Chain", "Gopher gallery", "Misc", "Notes", "Tools"},
+			substring: []string{"Chain", "Gopher gallery", "Misc", "Notes", "Tools"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := repo.Bookmarks().Search(tt.query, nil, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, b := range found {
+				got = append(got, b.Title)
+			}
+			want := tt.substring
+			if repo.FullTextSearch() {
+				want = tt.fts
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Search(%q) = %q, want %q (full-text index: %v)", tt.query, got, want, repo.FullTextSearch())
+			}
+		})
+	}
+}
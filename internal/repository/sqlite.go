@@ -2,6 +2,8 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/dastanaron/bookmarks/internal/models"
 
@@ -11,75 +13,74 @@ import (
 // SQLiteRepository implements Repository using SQLite
 type SQLiteRepository struct {
 	db        *sql.DB
+	tx        *sql.Tx // set for the repository passed to a Transaction function
 	bookmarks *bookmarkRepo
 	folders   *folderRepo
+	trash     *trashRepo
 }
 
 // NewSQLiteRepository creates a new SQLite repository
 func NewSQLiteRepository(dbPath string) (*SQLiteRepository, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	// Foreign keys are a per-connection setting in SQLite, off by default
+	db, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
 	if err != nil {
 		return nil, err
 	}
 
-	if err := initSchema(db); err != nil {
+	if err := migrate(db); err != nil {
 		db.Close()
 		return nil, err
 	}
 
-	repo := &SQLiteRepository{
-		db: db,
+	fts, err := syncSearchIndex(db)
+	if err != nil {
+		db.Close()
+		return nil, err
 	}
-	repo.bookmarks = &bookmarkRepo{db: db}
-	repo.folders = &folderRepo{db: db}
 
-	return repo, nil
+	return newSQLiteRepository(db, nil, db, fts), nil
 }
 
-func initSchema(db *sql.DB) error {
-	createTables := `
-	CREATE TABLE IF NOT EXISTS folders (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL,
-		parent_id INTEGER,
-		FOREIGN KEY(parent_id) REFERENCES folders(id)
-	);
-
-	CREATE TABLE IF NOT EXISTS bookmarks (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		title TEXT NOT NULL,
-		url TEXT NOT NULL,
-		description TEXT,
-		icon TEXT,
-		folder_id INTEGER,
-		FOREIGN KEY(folder_id) REFERENCES folders(id)
-	);
+// newSQLiteRepository creates a repository running its statements on q,
+// which is either db itself or tx
+func newSQLiteRepository(db *sql.DB, tx *sql.Tx, q querier, fts bool) *SQLiteRepository {
+	return &SQLiteRepository{
+		db:        db,
+		tx:        tx,
+		bookmarks: &bookmarkRepo{db: q, fts: fts},
+		folders:   &folderRepo{db: q},
+		trash:     &trashRepo{db: q},
+	}
+}
 
-	CREATE INDEX IF NOT EXISTS idx_bookmarks_folder ON bookmarks(folder_id);
-	CREATE INDEX IF NOT EXISTS idx_folders_parent ON folders(parent_id);
-	`
-	if _, err := db.Exec(createTables); err != nil {
-		return err
+// Transaction runs fn with a repository whose changes are committed together
+// if fn returns nil and rolled back otherwise. Called on the repository of a
+// transaction, fn joins that transaction.
+func (r *SQLiteRepository) Transaction(fn func(repo Repository) error) error {
+	if r.tx != nil {
+		return fn(r)
 	}
+	return withTx(r.db, func(tx *sql.Tx) error {
+		return fn(newSQLiteRepository(r.db, tx, tx, r.bookmarks.fts))
+	})
+}
 
-	// Migration: add icon column if it doesn't exist
-	// SQLite doesn't support IF NOT EXISTS for ALTER TABLE ADD COLUMN,
-	// so we check if the column exists first
-	var count int
-	err := db.QueryRow(`
-		SELECT COUNT(*) FROM pragma_table_info('bookmarks') WHERE name = 'icon'
-	`).Scan(&count)
+// withTx runs fn inside a transaction, committing on success and rolling
+// back if fn returns an error. If q already is a transaction, fn runs in it
+// and the owner of that transaction commits or rolls back.
+func withTx(q querier, fn func(tx *sql.Tx) error) error {
+	if tx, ok := q.(*sql.Tx); ok {
+		return fn(tx)
+	}
+	tx, err := q.(*sql.DB).Begin()
 	if err != nil {
 		return err
 	}
-	if count == 0 {
-		_, err = db.Exec(`ALTER TABLE bookmarks ADD COLUMN icon TEXT`)
-		if err != nil {
-			return err
-		}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
 	}
-
-	return nil
+	return tx.Commit()
 }
 
 // Bookmarks returns the bookmark repository
@@ -92,107 +93,238 @@ func (r *SQLiteRepository) Folders() FolderRepository {
 	return r.folders
 }
 
-// Close closes the database connection
+// Trash returns the trash repository
+func (r *SQLiteRepository) Trash() TrashRepository {
+	return r.trash
+}
+
+// FullTextSearch reports whether Search uses the FTS5 index
+func (r *SQLiteRepository) FullTextSearch() bool {
+	return r.bookmarks.fts
+}
+
+// Close closes the database connection. The repository passed to a
+// Transaction function has nothing to close.
 func (r *SQLiteRepository) Close() error {
+	if r.tx != nil {
+		return nil
+	}
 	return r.db.Close()
 }
 
+// unixTime scans a nullable unix timestamp column into a time.Time,
+// leaving it zero for NULL
+type unixTime struct {
+	t *time.Time
+}
+
+func (u unixTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*u.t = time.Time{}
+	case int64:
+		*u.t = time.Unix(v, 0)
+	default:
+		return fmt.Errorf("unsupported timestamp value %T", value)
+	}
+	return nil
+}
+
+// nullableUnix converts a time to a unix timestamp, or NULL if it is zero
+func nullableUnix(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Unix()
+}
+
+// nullableID converts a zero ID to NULL, which makes SQLite assign a new one
+func nullableID(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// nullableString converts an empty string to NULL
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// now returns the current time truncated to the precision stored in the database
+func now() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
 // bookmarkRepo implements BookmarkRepository
 type bookmarkRepo struct {
-	db *sql.DB
+	db  querier
+	fts bool // full-text index is available
 }
 
-func (r *bookmarkRepo) List() ([]models.Bookmark, error) {
-	rows, err := r.db.Query(`
-		SELECT b.id, b.title, b.url, b.description, b.icon, b.folder_id, f.name 
-		FROM bookmarks AS b
-		LEFT JOIN folders AS f ON f.id = b.folder_id 
-		WHERE b.url <> '' 
-		ORDER BY b.title
-	`)
-	if err != nil {
-		return nil, err
-	}
+// bookmarkSelect selects bookmark columns joined with the folder name,
+// in the order expected by scanBookmark. Callers must exclude trashed
+// bookmarks (b.deleted_at IS NULL) where appropriate.
+const bookmarkSelect = `
+	SELECT b.id, b.title, b.url, COALESCE(b.description, ''), COALESCE(b.keyword, ''), b.icon, b.read_later, b.folder_id, f.name,
+		b.created_at, b.updated_at, b.last_visited_at, b.deleted_at
+	FROM bookmarks AS b
+	LEFT JOIN folders AS f ON f.id = b.folder_id
+`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBookmark(row rowScanner, b *models.Bookmark) error {
+	return row.Scan(&b.ID, &b.Title, &b.URL, &b.Description, &b.Keyword, &b.Icon, &b.ReadLater, &b.FolderID, &b.FolderName,
+		unixTime{&b.CreatedAt}, unixTime{&b.UpdatedAt}, unixTime{&b.LastVisitedAt}, unixTime{&b.DeletedAt})
+}
+
+// scanBookmarks reads all rows selected with bookmarkSelect and loads their tags
+func scanBookmarks(q querier, rows *sql.Rows) ([]models.Bookmark, error) {
 	defer rows.Close()
 
 	var bookmarks []models.Bookmark
 	for rows.Next() {
 		var b models.Bookmark
-		if err := rows.Scan(&b.ID, &b.Title, &b.URL, &b.Description, &b.Icon, &b.FolderID, &b.FolderName); err != nil {
+		if err := scanBookmark(rows, &b); err != nil {
 			return nil, err
 		}
 		bookmarks = append(bookmarks, b)
 	}
-	return bookmarks, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	return bookmarks, loadTags(q, bookmarks)
 }
 
-func (r *bookmarkRepo) GetByID(id int) (*models.Bookmark, error) {
+// getBookmark returns a single bookmark selected with bookmarkSelect, or nil if not found
+func getBookmark(q querier, query string, args ...interface{}) (*models.Bookmark, error) {
 	var b models.Bookmark
-	err := r.db.QueryRow(`
-		SELECT b.id, b.title, b.url, b.description, b.icon, b.folder_id, f.name 
-		FROM bookmarks AS b
-		LEFT JOIN folders AS f ON f.id = b.folder_id 
-		WHERE b.id = ?
-	`, id).Scan(&b.ID, &b.Title, &b.URL, &b.Description, &b.Icon, &b.FolderID, &b.FolderName)
-
+	err := scanBookmark(q.QueryRow(query, args...), &b)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	if b.Tags, err = bookmarkTags(q, b.ID); err != nil {
+		return nil, err
+	}
 	return &b, nil
 }
 
+func (r *bookmarkRepo) List() ([]models.Bookmark, error) {
+	rows, err := r.db.Query(bookmarkSelect + `
+		WHERE b.url <> '' AND b.deleted_at IS NULL
+		ORDER BY b.title
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookmarks(r.db, rows)
+}
+
+// GetByID returns a bookmark by ID, including a trashed one
+func (r *bookmarkRepo) GetByID(id int) (*models.Bookmark, error) {
+	return getBookmark(r.db, bookmarkSelect+`WHERE b.id = ?`, id)
+}
+
 func (r *bookmarkRepo) GetByURL(url string) (*models.Bookmark, error) {
-	var b models.Bookmark
-	err := r.db.QueryRow(`
-		SELECT b.id, b.title, b.url, b.description, b.icon, b.folder_id, f.name 
-		FROM bookmarks AS b
-		LEFT JOIN folders AS f ON f.id = b.folder_id 
-		WHERE b.url = ?
-	`, url).Scan(&b.ID, &b.Title, &b.URL, &b.Description, &b.Icon, &b.FolderID, &b.FolderName)
+	return getBookmark(r.db, bookmarkSelect+`WHERE b.url = ? AND b.deleted_at IS NULL ORDER BY b.id`, url)
+}
 
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+func (r *bookmarkRepo) ListByURL(url string) ([]models.Bookmark, error) {
+	rows, err := r.db.Query(bookmarkSelect+`WHERE b.url = ? AND b.deleted_at IS NULL ORDER BY b.id`, url)
 	if err != nil {
 		return nil, err
 	}
-	return &b, nil
+	return scanBookmarks(r.db, rows)
 }
 
+// Create creates a bookmark. Zero timestamps are set to the current time,
+// so importers can preserve the original creation date. A non-zero b.ID is
+// used as the ID of the new bookmark, so a backup can be restored as is.
 func (r *bookmarkRepo) Create(b *models.Bookmark) error {
-	res, err := r.db.Exec(
-		`INSERT INTO bookmarks(title, url, description, icon, folder_id) VALUES (?, ?, ?, ?, ?)`,
-		b.Title, b.URL, b.Description, b.Icon, b.FolderID,
-	)
-	if err != nil {
-		return err
+	if b.CreatedAt.IsZero() {
+		b.CreatedAt = now()
 	}
-	id, err := res.LastInsertId()
-	if err != nil {
-		return err
+	if b.UpdatedAt.IsZero() {
+		b.UpdatedAt = b.CreatedAt
 	}
-	b.ID = int(id)
-	return nil
+
+	return withTx(r.db, func(tx *sql.Tx) error {
+		res, err := tx.Exec(
+			`INSERT INTO bookmarks(id, title, url, description, keyword, icon, read_later, folder_id, created_at, updated_at, last_visited_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			nullableID(b.ID), b.Title, b.URL, b.Description, nullableString(b.Keyword), b.Icon, b.ReadLater, b.FolderID,
+			b.CreatedAt.Unix(), b.UpdatedAt.Unix(), nullableUnix(b.LastVisitedAt),
+		)
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		b.ID = int(id)
+		return addTags(tx, b.ID, b.Tags)
+	})
 }
 
+// Update updates a bookmark and sets its UpdatedAt to the current time.
+// Tags are replaced only if b.Tags is not nil.
 func (r *bookmarkRepo) Update(b *models.Bookmark) error {
-	_, err := r.db.Exec(
-		`UPDATE bookmarks SET title = ?, url = ?, description = ?, icon = ?, folder_id = ? WHERE id = ?`,
-		b.Title, b.URL, b.Description, b.Icon, b.FolderID, b.ID,
-	)
-	return err
+	b.UpdatedAt = now()
+	return r.update(b)
+}
+
+// update writes all fields of b. A creation or last visit time is only
+// filled in, never cleared, as most sources don't know them.
+func (r *bookmarkRepo) update(b *models.Bookmark) error {
+	return withTx(r.db, func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`UPDATE bookmarks SET title = ?, url = ?, description = ?,
+				keyword = ?, icon = ?, read_later = ?, folder_id = ?,
+				created_at = COALESCE(created_at, ?), updated_at = ?,
+				last_visited_at = COALESCE(?, last_visited_at)
+			WHERE id = ?`,
+			b.Title, b.URL, b.Description, nullableString(b.Keyword), b.Icon, b.ReadLater, b.FolderID,
+			nullableUnix(b.CreatedAt), b.UpdatedAt.Unix(), nullableUnix(b.LastVisitedAt),
+			b.ID,
+		)
+		if err != nil || b.Tags == nil {
+			return err
+		}
+		return setTags(tx, b.ID, b.Tags)
+	})
 }
 
+// Replace keeps a non-zero b.UpdatedAt, so importers can preserve the
+// original modification date
+func (r *bookmarkRepo) Replace(b *models.Bookmark) error {
+	if b.UpdatedAt.IsZero() {
+		b.UpdatedAt = now()
+	}
+	return r.update(b)
+}
+
+// Upsert updates the oldest bookmark with the URL like Replace, the one
+// GetByURL returns. Trashed bookmarks are left in the trash and don't count
+// as existing.
 func (r *bookmarkRepo) Upsert(b *models.Bookmark) (bool, error) {
 	var id int
-	err := r.db.QueryRow(`SELECT id FROM bookmarks WHERE url = ?`, b.URL).Scan(&id)
+	err := r.db.QueryRow(`SELECT id FROM bookmarks WHERE url = ? AND deleted_at IS NULL ORDER BY id LIMIT 1`, b.URL).Scan(&id)
 	switch err {
 	case nil:
 		b.ID = id
-		return false, r.Update(b)
+		return false, r.Replace(b)
 	case sql.ErrNoRows:
 		return true, r.Create(b)
 	default:
@@ -200,18 +332,29 @@ func (r *bookmarkRepo) Upsert(b *models.Bookmark) (bool, error) {
 	}
 }
 
+// MarkVisited sets the last visit time of a bookmark to the current time
+func (r *bookmarkRepo) MarkVisited(id int) error {
+	_, err := r.db.Exec(`UPDATE bookmarks SET last_visited_at = ? WHERE id = ?`, now().Unix(), id)
+	return err
+}
+
 func (r *bookmarkRepo) Delete(id int) error {
-	_, err := r.db.Exec(`DELETE FROM bookmarks WHERE id = ?`, id)
+	_, err := r.db.Exec(`UPDATE bookmarks SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, now().Unix(), id)
 	return err
 }
 
 // folderRepo implements FolderRepository
 type folderRepo struct {
-	db *sql.DB
+	db querier
 }
 
 func (r *folderRepo) List() ([]models.Folder, error) {
-	rows, err := r.db.Query(`SELECT id, name, parent_id FROM folders ORDER BY name`)
+	rows, err := r.db.Query(`
+		SELECT id, name, parent_id, toolbar, created_at, updated_at
+		FROM folders
+		WHERE deleted_at IS NULL
+		ORDER BY name
+	`)
 	if err != nil {
 		return nil, err
 	}
@@ -220,7 +363,7 @@ func (r *folderRepo) List() ([]models.Folder, error) {
 	var folders []models.Folder
 	for rows.Next() {
 		var f models.Folder
-		if err := rows.Scan(&f.ID, &f.Name, &f.ParentID); err != nil {
+		if err := rows.Scan(&f.ID, &f.Name, &f.ParentID, &f.Toolbar, unixTime{&f.CreatedAt}, unixTime{&f.UpdatedAt}); err != nil {
 			return nil, err
 		}
 		folders = append(folders, f)
@@ -228,10 +371,11 @@ func (r *folderRepo) List() ([]models.Folder, error) {
 	return folders, rows.Err()
 }
 
+// GetByID returns a folder by ID, including a trashed one
 func (r *folderRepo) GetByID(id int) (*models.Folder, error) {
 	var f models.Folder
-	err := r.db.QueryRow(`SELECT id, name, parent_id FROM folders WHERE id = ?`, id).
-		Scan(&f.ID, &f.Name, &f.ParentID)
+	err := r.db.QueryRow(`SELECT id, name, parent_id, toolbar, created_at, updated_at, deleted_at FROM folders WHERE id = ?`, id).
+		Scan(&f.ID, &f.Name, &f.ParentID, &f.Toolbar, unixTime{&f.CreatedAt}, unixTime{&f.UpdatedAt}, unixTime{&f.DeletedAt})
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -243,51 +387,122 @@ func (r *folderRepo) GetByID(id int) (*models.Folder, error) {
 }
 
 func (r *folderRepo) Create(name string, parentID *int) (*models.Folder, error) {
-	res, err := r.db.Exec(`INSERT INTO folders(name, parent_id) VALUES (?, ?)`, name, parentID)
-	if err != nil {
+	f := &models.Folder{Name: name, ParentID: parentID}
+	if err := r.Insert(f); err != nil {
 		return nil, err
 	}
+	return f, nil
+}
+
+// Insert creates a folder from f. Zero timestamps are set to the current
+// time and a non-zero f.ID is used as the ID of the new folder, like
+// bookmarkRepo.Create.
+func (r *folderRepo) Insert(f *models.Folder) error {
+	if f.CreatedAt.IsZero() {
+		f.CreatedAt = now()
+	}
+	if f.UpdatedAt.IsZero() {
+		f.UpdatedAt = f.CreatedAt
+	}
+	res, err := r.db.Exec(`INSERT INTO folders(id, name, parent_id, toolbar, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		nullableID(f.ID), f.Name, f.ParentID, f.Toolbar, f.CreatedAt.Unix(), f.UpdatedAt.Unix())
+	if err != nil {
+		return err
+	}
 	id, err := res.LastInsertId()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &models.Folder{ID: int(id), Name: name, ParentID: parentID}, nil
+	f.ID = int(id)
+	return nil
 }
 
 func (r *folderRepo) Update(f *models.Folder) error {
-	_, err := r.db.Exec(`UPDATE folders SET name = ?, parent_id = ? WHERE id = ?`,
-		f.Name, f.ParentID, f.ID)
+	f.UpdatedAt = now()
+	_, err := r.db.Exec(`UPDATE folders SET name = ?, parent_id = ?, updated_at = ? WHERE id = ?`,
+		f.Name, f.ParentID, f.UpdatedAt.Unix(), f.ID)
 	return err
 }
 
-func (r *folderRepo) Delete(id int) error {
-	_, err := r.db.Exec(`DELETE FROM folders WHERE id = ?`, id)
-	return err
+func (r *folderRepo) Delete(id int, policy models.FolderDeletePolicy) error {
+	stamp := now().Unix()
+	return withTx(r.db, func(tx *sql.Tx) error {
+		var parentID *int
+		err := tx.QueryRow(`SELECT parent_id FROM folders WHERE id = ? AND deleted_at IS NULL`, id).Scan(&parentID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("folder %d not found", id)
+		}
+		if err != nil {
+			return err
+		}
+
+		switch policy {
+		case models.FolderDeleteCascade:
+			return trashSubtree(tx, id, stamp)
+		case models.FolderDeleteMoveToParent:
+			_, err = tx.Exec(`UPDATE folders SET parent_id = ?, updated_at = ? WHERE parent_id = ? AND deleted_at IS NULL`,
+				parentID, stamp, id)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`UPDATE bookmarks SET folder_id = ?, updated_at = ? WHERE folder_id = ? AND deleted_at IS NULL`,
+				parentID, stamp, id)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`UPDATE folders SET deleted_at = ? WHERE id = ?`, stamp, id)
+			return err
+		default:
+			return fmt.Errorf("unknown folder delete policy %d", policy)
+		}
+	})
 }
 
-func (r *folderRepo) Upsert(name string, parentID *int) (*models.Folder, error) {
+func (r *folderRepo) CountContents(id int) (int, int, error) {
+	var bookmarks, folders int
+	err := r.db.QueryRow(subtreeCTE+`
+		SELECT
+			(SELECT COUNT(*) FROM bookmarks
+				WHERE folder_id IN (SELECT id FROM subtree) AND url <> '' AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM folders
+				WHERE id IN (SELECT id FROM subtree) AND id <> ? AND deleted_at IS NULL)
+	`, id, id).Scan(&bookmarks, &folders)
+	return bookmarks, folders, err
+}
+
+// Find returns the folder with the given name in the folder parentID
+// (nil for the root), or nil if there is none. Trashed folders are ignored.
+func (r *folderRepo) Find(name string, parentID *int) (*models.Folder, error) {
 	var id int
 	var err error
 
 	// SQL for NULL check requires different queries
 	if parentID == nil {
 		err = r.db.QueryRow(
-			`SELECT id FROM folders WHERE name = ? AND parent_id IS NULL`,
+			`SELECT id FROM folders WHERE name = ? AND parent_id IS NULL AND deleted_at IS NULL`,
 			name,
 		).Scan(&id)
 	} else {
 		err = r.db.QueryRow(
-			`SELECT id FROM folders WHERE name = ? AND parent_id = ?`,
+			`SELECT id FROM folders WHERE name = ? AND parent_id = ? AND deleted_at IS NULL`,
 			name, *parentID,
 		).Scan(&id)
 	}
 
-	if err == nil {
-		return &models.Folder{ID: id, Name: name, ParentID: parentID}, nil
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-	if err != sql.ErrNoRows {
+	if err != nil {
 		return nil, err
 	}
+	return &models.Folder{ID: id, Name: name, ParentID: parentID}, nil
+}
+
+func (r *folderRepo) Upsert(name string, parentID *int) (*models.Folder, error) {
+	folder, err := r.Find(name, parentID)
+	if err != nil || folder != nil {
+		return folder, err
+	}
 
 	// Create new folder
 	return r.Create(name, parentID)
@@ -307,9 +522,15 @@ func (r *folderRepo) GetFolderContent(folderID *int) ([]models.Item, error) {
 				b.url,
 				b.description,
 				b.icon,
-				b.folder_id as parent_id
+				b.keyword,
+				b.read_later,
+				b.folder_id as parent_id,
+				b.created_at,
+				b.updated_at,
+				b.last_visited_at,
+				b.deleted_at
 			FROM bookmarks AS b
-			WHERE b.folder_id IS NULL
+			WHERE b.folder_id IS NULL AND b.deleted_at IS NULL
 			UNION ALL
 			SELECT 
 				'folder' as type,
@@ -318,9 +539,15 @@ func (r *folderRepo) GetFolderContent(folderID *int) ([]models.Item, error) {
 				NULL as url,
 				NULL as description,
 				NULL as icon,
-				f.parent_id as parent_id
+				NULL as keyword,
+				0 as read_later,
+				f.parent_id as parent_id,
+				f.created_at,
+				f.updated_at,
+				NULL as last_visited_at,
+				f.deleted_at
 			FROM folders AS f
-			WHERE f.parent_id IS NULL OR f.parent_id = 0
+			WHERE (f.parent_id IS NULL OR f.parent_id = 0) AND f.deleted_at IS NULL
 			ORDER BY type, name
 		`
 		args = []interface{}{}
@@ -334,9 +561,15 @@ func (r *folderRepo) GetFolderContent(folderID *int) ([]models.Item, error) {
 				b.url,
 				b.description,
 				b.icon,
-				b.folder_id as parent_id
+				b.keyword,
+				b.read_later,
+				b.folder_id as parent_id,
+				b.created_at,
+				b.updated_at,
+				b.last_visited_at,
+				b.deleted_at
 			FROM bookmarks AS b
-			WHERE b.folder_id = ?
+			WHERE b.folder_id = ? AND b.deleted_at IS NULL
 			UNION ALL
 			SELECT 
 				'folder' as type,
@@ -345,9 +578,15 @@ func (r *folderRepo) GetFolderContent(folderID *int) ([]models.Item, error) {
 				NULL as url,
 				NULL as description,
 				NULL as icon,
-				f.parent_id as parent_id
+				NULL as keyword,
+				0 as read_later,
+				f.parent_id as parent_id,
+				f.created_at,
+				f.updated_at,
+				NULL as last_visited_at,
+				f.deleted_at
 			FROM folders AS f
-			WHERE f.parent_id = ?
+			WHERE f.parent_id = ? AND f.deleted_at IS NULL
 			ORDER BY type, name
 		`
 		args = []interface{}{*folderID, *folderID}
@@ -357,13 +596,20 @@ func (r *folderRepo) GetFolderContent(folderID *int) ([]models.Item, error) {
 	if err != nil {
 		return nil, err
 	}
+	return scanItems(r.db, rows)
+}
+
+// scanItems reads rows of (type, id, name, url, description, icon, keyword,
+// read_later, parent_id, created_at, updated_at, last_visited_at, deleted_at) and attaches tags
+// to bookmark items
+func scanItems(q querier, rows *sql.Rows) ([]models.Item, error) {
 	defer rows.Close()
 
 	var items []models.Item
 	for rows.Next() {
 		var item models.Item
 		var typeStr string
-		var url, description, icon sql.NullString
+		var url, description, icon, keyword sql.NullString
 		var parentID sql.NullInt64
 
 		err := rows.Scan(
@@ -373,7 +619,13 @@ func (r *folderRepo) GetFolderContent(folderID *int) ([]models.Item, error) {
 			&url,
 			&description,
 			&icon,
+			&keyword,
+			&item.ReadLater,
 			&parentID,
+			unixTime{&item.CreatedAt},
+			unixTime{&item.UpdatedAt},
+			unixTime{&item.LastVisitedAt},
+			unixTime{&item.DeletedAt},
 		)
 		if err != nil {
 			return nil, err
@@ -389,6 +641,7 @@ func (r *folderRepo) GetFolderContent(folderID *int) ([]models.Item, error) {
 		if icon.Valid {
 			item.Icon = &icon.String
 		}
+		item.Keyword = keyword.String
 		if parentID.Valid {
 			pid := int(parentID.Int64)
 			item.ParentID = &pid
@@ -396,6 +649,27 @@ func (r *folderRepo) GetFolderContent(folderID *int) ([]models.Item, error) {
 
 		items = append(items, item)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// Attach tags to bookmark items
+	var bookmarkIDs []int
+	for _, item := range items {
+		if item.Type == models.ItemTypeBookmark {
+			bookmarkIDs = append(bookmarkIDs, item.ID)
+		}
+	}
+	tagsByID, err := tagsByBookmark(q, bookmarkIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].Type == models.ItemTypeBookmark {
+			items[i].Tags = tagsByID[items[i].ID]
+		}
+	}
 
-	return items, rows.Err()
+	return items, nil
 }
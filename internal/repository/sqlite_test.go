@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+func TestUpdateWritesFieldsAsGiven(t *testing.T) {
+	repo := openRepo(t, createDB(t, ""))
+	icon := "aWNvbg=="
+	b := &models.Bookmark{Title: "Wikipedia", URL: "https://en.wikipedia.org/", Keyword: "w", Icon: &icon, ReadLater: true}
+	if err := repo.Bookmarks().Create(b); err != nil {
+		t.Fatal(err)
+	}
+
+	// Clearing the keyword, icon and read-later flag sticks
+	b.Keyword, b.Icon, b.ReadLater = "", nil, false
+	if err := repo.Bookmarks().Update(b); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.Bookmarks().GetByID(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Keyword != "" || got.Icon != nil || got.ReadLater {
+		t.Errorf("after clearing: keyword = %q, icon = %v, read later = %v", got.Keyword, got.Icon, got.ReadLater)
+	}
+
+	// Setting them again sticks too
+	got.Keyword, got.ReadLater = "wp", true
+	if err := repo.Bookmarks().Update(got); err != nil {
+		t.Fatal(err)
+	}
+	items, err := repo.Folders().GetFolderContent(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Keyword != "wp" || !items[0].ReadLater {
+		t.Errorf("GetFolderContent = %+v, want the bookmark with keyword wp to read later", items)
+	}
+}
+
+func TestFolderDelete(t *testing.T) {
+	for _, policy := range []models.FolderDeletePolicy{models.FolderDeleteCascade, models.FolderDeleteMoveToParent} {
+		cascade := policy == models.FolderDeleteCascade
+		name := "move to parent"
+		if cascade {
+			name = "cascade"
+		}
+		t.Run(name, func(t *testing.T) {
+			repo := openRepo(t, createDB(t, ""))
+			parent := newFolder(t, repo, "Parent", nil)
+			work := newFolder(t, repo, "Work", &parent)
+			sub := newFolder(t, repo, "Sub", &work)
+			inWork := newBookmark(t, repo, "in-work", &work)
+			inSub := newBookmark(t, repo, "in-sub", &sub)
+			earlier := time.Now().Add(-time.Hour).Truncate(time.Second)
+			trashed := newBookmark(t, repo, "trashed", &work)
+			trashedAt(t, repo, "bookmarks", trashed, earlier)
+
+			// Trashed bookmarks and the folder itself are not counted
+			bookmarks, folders, err := repo.Folders().CountContents(work)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bookmarks != 2 || folders != 1 {
+				t.Errorf("CountContents(Work) = %d bookmarks, %d folders, want 2 and 1", bookmarks, folders)
+			}
+
+			if err := repo.Folders().Delete(work, policy); err != nil {
+				t.Fatal(err)
+			}
+
+			if f := folderAt(t, repo, work); f.DeletedAt.IsZero() {
+				t.Error("Work is not in the trash")
+			}
+			if f := folderAt(t, repo, parent); !f.DeletedAt.IsZero() {
+				t.Error("Parent was trashed")
+			}
+			s, b1, b2 := folderAt(t, repo, sub), bookmarkAt(t, repo, inWork), bookmarkAt(t, repo, inSub)
+			if cascade {
+				// Everything below Work is trashed in place
+				if s.DeletedAt.IsZero() || b1.DeletedAt.IsZero() || b2.DeletedAt.IsZero() {
+					t.Errorf("after cascade: Sub, in-work, in-sub trashed at %v, %v, %v; want all trashed", s.DeletedAt, b1.DeletedAt, b2.DeletedAt)
+				}
+				if !sameIntPtr(s.ParentID, &work) || !sameIntPtr(b1.FolderID, &work) || !sameIntPtr(b2.FolderID, &sub) {
+					t.Error("cascade moved the contents of Work")
+				}
+			} else {
+				// The direct children of Work move to Parent, the rest stays below them
+				if !s.DeletedAt.IsZero() || !b1.DeletedAt.IsZero() || !b2.DeletedAt.IsZero() {
+					t.Errorf("after move: Sub, in-work, in-sub trashed at %v, %v, %v; want none trashed", s.DeletedAt, b1.DeletedAt, b2.DeletedAt)
+				}
+				if !sameIntPtr(s.ParentID, &parent) || !sameIntPtr(b1.FolderID, &parent) || !sameIntPtr(b2.FolderID, &sub) {
+					t.Errorf("after move: Sub in %v, in-work in %v, in-sub in %v; want Parent, Parent and Sub",
+						fmtIntPtr(s.ParentID), fmtIntPtr(b1.FolderID), fmtIntPtr(b2.FolderID))
+				}
+				bookmarks, folders, err := repo.Folders().CountContents(parent)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if bookmarks != 2 || folders != 1 {
+					t.Errorf("CountContents(Parent) = %d bookmarks, %d folders, want 2 and 1", bookmarks, folders)
+				}
+			}
+
+			// A bookmark trashed before keeps its folder and trash time
+			if b := bookmarkAt(t, repo, trashed); !b.DeletedAt.Equal(earlier) || !sameIntPtr(b.FolderID, &work) {
+				t.Errorf("trashed bookmark: folder %v, deleted at %v; want Work and %v", fmtIntPtr(b.FolderID), b.DeletedAt, earlier)
+			}
+
+			// Deleting it again fails
+			if err := repo.Folders().Delete(work, policy); err == nil {
+				t.Error("deleting a trashed folder succeeded")
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func (r *bookmarkRepo) AddTags(bookmarkID int, tags []string) error {
+	return withTx(r.db, func(tx *sql.Tx) error {
+		return addTags(tx, bookmarkID, tags)
+	})
+}
+
+func (r *bookmarkRepo) RemoveTags(bookmarkID int, tags []string) error {
+	tags = normalizeTags(tags)
+	if len(tags) == 0 {
+		return nil
+	}
+	return withTx(r.db, func(tx *sql.Tx) error {
+		args := []interface{}{bookmarkID}
+		for _, t := range tags {
+			args = append(args, t)
+		}
+		_, err := tx.Exec(`
+			DELETE FROM bookmark_tags
+			WHERE bookmark_id = ? AND tag_id IN (SELECT id FROM tags WHERE name IN (`+placeholders(len(tags))+`))
+		`, args...)
+		if err != nil {
+			return err
+		}
+		return pruneTags(tx)
+	})
+}
+
+func (r *bookmarkRepo) ListTags() ([]models.Tag, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.name, COUNT(b.id)
+		FROM tags AS t
+		LEFT JOIN bookmark_tags AS bt ON bt.tag_id = t.id
+		LEFT JOIN bookmarks AS b ON b.id = bt.bookmark_id AND b.deleted_at IS NULL
+		GROUP BY t.id
+		ORDER BY t.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []models.Tag
+	for rows.Next() {
+		var t models.Tag
+		if err := rows.Scan(&t.ID, &t.Name, &t.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+func (r *bookmarkRepo) RenameTag(oldName, newName string) error {
+	oldName = strings.TrimSpace(oldName)
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return fmt.Errorf("tag name cannot be empty")
+	}
+
+	return withTx(r.db, func(tx *sql.Tx) error {
+		oldID, err := findTag(tx, oldName)
+		if err != nil {
+			return err
+		}
+		if oldID == 0 {
+			return fmt.Errorf("tag %q not found", oldName)
+		}
+
+		// Names are case-insensitive, so renaming "go" to "Go" finds itself
+		newID, err := findTag(tx, newName)
+		if err != nil {
+			return err
+		}
+		if newID != 0 && newID != oldID {
+			return fmt.Errorf("tag %q already exists, merge the tags instead", newName)
+		}
+
+		_, err = tx.Exec(`UPDATE tags SET name = ? WHERE id = ?`, newName, oldID)
+		return err
+	})
+}
+
+func (r *bookmarkRepo) MergeTags(sources []string, target string) error {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return fmt.Errorf("tag name cannot be empty")
+	}
+
+	return withTx(r.db, func(tx *sql.Tx) error {
+		targetID, err := ensureTag(tx, target)
+		if err != nil {
+			return err
+		}
+
+		for _, source := range normalizeTags(sources) {
+			sourceID, err := findTag(tx, source)
+			if err != nil {
+				return err
+			}
+			if sourceID == 0 {
+				return fmt.Errorf("tag %q not found", source)
+			}
+			if sourceID == targetID {
+				continue
+			}
+
+			_, err = tx.Exec(`
+				INSERT OR IGNORE INTO bookmark_tags(bookmark_id, tag_id)
+				SELECT bookmark_id, ? FROM bookmark_tags WHERE tag_id = ?
+			`, targetID, sourceID)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(`DELETE FROM bookmark_tags WHERE tag_id = ?`, sourceID); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(`DELETE FROM tags WHERE id = ?`, sourceID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *bookmarkRepo) ListByTags(tags []string, matchAll bool) ([]models.Bookmark, error) {
+	tags = normalizeTags(tags)
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	required := 1
+	if matchAll {
+		required = len(tags)
+	}
+
+	args := make([]interface{}, 0, len(tags)+1)
+	for _, t := range tags {
+		args = append(args, t)
+	}
+	args = append(args, required)
+
+	rows, err := r.db.Query(bookmarkSelect+`
+		JOIN bookmark_tags AS bt ON bt.bookmark_id = b.id
+		JOIN tags AS t ON t.id = bt.tag_id
+		WHERE t.name IN (`+placeholders(len(tags))+`) AND b.deleted_at IS NULL
+		GROUP BY b.id
+		HAVING COUNT(DISTINCT t.id) >= ?
+		ORDER BY b.title
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookmarks(r.db, rows)
+}
+
+// loadTags fills the Tags field of the given bookmarks
+func loadTags(q querier, bookmarks []models.Bookmark) error {
+	if len(bookmarks) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(bookmarks))
+	for i := range bookmarks {
+		ids[i] = bookmarks[i].ID
+	}
+	tagsByID, err := tagsByBookmark(q, ids)
+	if err != nil {
+		return err
+	}
+	for i := range bookmarks {
+		bookmarks[i].Tags = tagsByID[bookmarks[i].ID]
+	}
+	return nil
+}
+
+// tagsByBookmark returns tag names keyed by bookmark ID
+func tagsByBookmark(q querier, ids []int) (map[int][]string, error) {
+	result := make(map[int][]string)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	// SQLite limits the number of bound parameters, so large sets
+	// (e.g. List() on a big database) are loaded in one pass instead
+	query := `
+		SELECT bt.bookmark_id, t.name
+		FROM bookmark_tags AS bt
+		JOIN tags AS t ON t.id = bt.tag_id
+	`
+	var args []interface{}
+	if len(ids) <= 500 {
+		query += ` WHERE bt.bookmark_id IN (` + placeholders(len(ids)) + `)`
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	query += ` ORDER BY t.name`
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		result[id] = append(result[id], name)
+	}
+	return result, rows.Err()
+}
+
+// bookmarkTags returns the tag names of a single bookmark
+func bookmarkTags(q querier, bookmarkID int) ([]string, error) {
+	tagsByID, err := tagsByBookmark(q, []int{bookmarkID})
+	if err != nil {
+		return nil, err
+	}
+	return tagsByID[bookmarkID], nil
+}
+
+// setTags replaces all tags of a bookmark
+func setTags(q querier, bookmarkID int, tags []string) error {
+	if _, err := q.Exec(`DELETE FROM bookmark_tags WHERE bookmark_id = ?`, bookmarkID); err != nil {
+		return err
+	}
+	if err := addTags(q, bookmarkID, tags); err != nil {
+		return err
+	}
+	return pruneTags(q)
+}
+
+// addTags attaches tags to a bookmark, creating missing tags
+func addTags(q querier, bookmarkID int, tags []string) error {
+	for _, name := range normalizeTags(tags) {
+		tagID, err := ensureTag(q, name)
+		if err != nil {
+			return err
+		}
+		_, err = q.Exec(`INSERT OR IGNORE INTO bookmark_tags(bookmark_id, tag_id) VALUES (?, ?)`, bookmarkID, tagID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureTag returns the ID of a tag, creating it if needed
+func ensureTag(q querier, name string) (int, error) {
+	id, err := findTag(q, name)
+	if err != nil || id != 0 {
+		return id, err
+	}
+	res, err := q.Exec(`INSERT INTO tags(name) VALUES (?)`, name)
+	if err != nil {
+		return 0, err
+	}
+	newID, err := res.LastInsertId()
+	return int(newID), err
+}
+
+// findTag returns the ID of a tag (case-insensitive), or 0 if it doesn't exist
+func findTag(q querier, name string) (int, error) {
+	var id int
+	err := q.QueryRow(`SELECT id FROM tags WHERE name = ?`, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// pruneTags deletes tags that are no longer attached to any bookmark
+func pruneTags(q querier) error {
+	_, err := q.Exec(`DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM bookmark_tags)`)
+	return err
+}
+
+// normalizeTags trims tag names and drops empty and duplicate (case-insensitive) ones
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		key := strings.ToLower(t)
+		if t == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, t)
+	}
+	return result
+}
+
+// placeholders returns "?, ?, ..." with n placeholders
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("?, ", n-1) + "?"
+}
@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// subtreeCTE selects the ID of folder ? and the IDs of all its descendants
+// into the "subtree" table
+const subtreeCTE = `
+	WITH RECURSIVE subtree(id) AS (
+		SELECT ?
+		UNION
+		SELECT f.id FROM folders AS f JOIN subtree ON f.parent_id = subtree.id
+	)
+`
+
+// ancestorsCTE selects folder ? and all its ancestors into the "chain" table
+const ancestorsCTE = `
+	WITH RECURSIVE chain(id, parent_id) AS (
+		SELECT id, parent_id FROM folders WHERE id = ?
+		UNION
+		SELECT f.id, f.parent_id FROM folders AS f JOIN chain ON f.id = chain.parent_id
+	)
+`
+
+// trashRepo implements TrashRepository
+type trashRepo struct {
+	db querier
+}
+
+func (r *trashRepo) List() ([]models.Item, error) {
+	// An item trashed on its own is listed even if its folder was trashed
+	// later, otherwise it could never be restored
+	rows, err := r.db.Query(`
+		SELECT
+			'bookmark' as type,
+			b.id,
+			b.title as name,
+			b.url,
+			b.description,
+			b.icon,
+			b.keyword,
+			b.read_later,
+			b.folder_id as parent_id,
+			b.created_at,
+			b.updated_at,
+			b.last_visited_at,
+			b.deleted_at as deleted_at
+		FROM bookmarks AS b
+		LEFT JOIN folders AS f ON f.id = b.folder_id
+		WHERE b.deleted_at IS NOT NULL
+			AND (f.id IS NULL OR f.deleted_at IS NULL OR f.deleted_at <> b.deleted_at)
+		UNION ALL
+		SELECT
+			'folder' as type,
+			f.id,
+			f.name,
+			NULL as url,
+			NULL as description,
+			NULL as icon,
+			NULL as keyword,
+			0 as read_later,
+			f.parent_id as parent_id,
+			f.created_at,
+			f.updated_at,
+			NULL as last_visited_at,
+			f.deleted_at as deleted_at
+		FROM folders AS f
+		LEFT JOIN folders AS p ON p.id = f.parent_id
+		WHERE f.deleted_at IS NOT NULL
+			AND (p.id IS NULL OR p.deleted_at IS NULL OR p.deleted_at <> f.deleted_at)
+		ORDER BY deleted_at DESC, type, name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanItems(r.db, rows)
+}
+
+func (r *trashRepo) Restore(itemType models.ItemType, id int) error {
+	return withTx(r.db, func(tx *sql.Tx) error {
+		switch itemType {
+		case models.ItemTypeBookmark:
+			return restoreBookmark(tx, id)
+		case models.ItemTypeFolder:
+			return restoreFolder(tx, id)
+		default:
+			return fmt.Errorf("unknown item type %q", itemType)
+		}
+	})
+}
+
+func (r *trashRepo) Purge(itemType models.ItemType, id int) error {
+	return withTx(r.db, func(tx *sql.Tx) error {
+		switch itemType {
+		case models.ItemTypeBookmark:
+			if _, err := tx.Exec(`DELETE FROM bookmarks WHERE id = ? AND deleted_at IS NOT NULL`, id); err != nil {
+				return err
+			}
+		case models.ItemTypeFolder:
+			var trashed int
+			err := tx.QueryRow(`SELECT COUNT(*) FROM folders WHERE id = ? AND deleted_at IS NOT NULL`, id).Scan(&trashed)
+			if err != nil || trashed == 0 {
+				return err
+			}
+			if _, err := tx.Exec(subtreeCTE+`DELETE FROM bookmarks WHERE folder_id IN (SELECT id FROM subtree)`, id); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(subtreeCTE+`DELETE FROM folders WHERE id IN (SELECT id FROM subtree)`, id); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown item type %q", itemType)
+		}
+		return pruneTags(tx)
+	})
+}
+
+func (r *trashRepo) Empty(before time.Time) (int, int, error) {
+	var bookmarks, folders int
+	err := withTx(r.db, func(tx *sql.Tx) error {
+		// Folders go with their whole subtree, whenever its items were trashed
+		purgeable := `
+			WITH RECURSIVE subtree(id) AS (
+				SELECT id FROM folders WHERE deleted_at IS NOT NULL AND deleted_at <= ?
+				UNION
+				SELECT f.id FROM folders AS f JOIN subtree ON f.parent_id = subtree.id
+			)
+		`
+		res, err := tx.Exec(purgeable+`DELETE FROM bookmarks WHERE folder_id IN (SELECT id FROM subtree)`, before.Unix())
+		if err != nil {
+			return err
+		}
+		n, _ := res.RowsAffected()
+		bookmarks += int(n)
+
+		res, err = tx.Exec(purgeable+`DELETE FROM folders WHERE id IN (SELECT id FROM subtree)`, before.Unix())
+		if err != nil {
+			return err
+		}
+		n, _ = res.RowsAffected()
+		folders += int(n)
+
+		res, err = tx.Exec(`DELETE FROM bookmarks WHERE deleted_at IS NOT NULL AND deleted_at <= ?`, before.Unix())
+		if err != nil {
+			return err
+		}
+		n, _ = res.RowsAffected()
+		bookmarks += int(n)
+
+		return pruneTags(tx)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return bookmarks, folders, nil
+}
+
+// trashSubtree moves folder id and everything below it to the trash.
+// Everything trashed together shares the same deleted_at, which is how
+// Restore finds it again.
+func trashSubtree(tx *sql.Tx, id int, deletedAt int64) error {
+	_, err := tx.Exec(subtreeCTE+`
+		UPDATE folders SET deleted_at = ? WHERE id IN (SELECT id FROM subtree) AND deleted_at IS NULL
+	`, id, deletedAt)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(subtreeCTE+`
+		UPDATE bookmarks SET deleted_at = ? WHERE folder_id IN (SELECT id FROM subtree) AND deleted_at IS NULL
+	`, id, deletedAt)
+	return err
+}
+
+// restoreBookmark takes a bookmark out of the trash. If its folder was
+// purged meanwhile, the bookmark is restored to the root.
+func restoreBookmark(tx *sql.Tx, id int) error {
+	var folderID sql.NullInt64
+	err := tx.QueryRow(`SELECT folder_id FROM bookmarks WHERE id = ?`, id).Scan(&folderID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("bookmark %d not found", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	var target *int
+	if folderID.Valid {
+		fid := int(folderID.Int64)
+		exists, err := restoreFolderChain(tx, fid)
+		if err != nil {
+			return err
+		}
+		if exists {
+			target = &fid
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE bookmarks SET deleted_at = NULL, folder_id = ? WHERE id = ?`, target, id)
+	return err
+}
+
+// restoreFolder takes a folder out of the trash together with everything
+// that was trashed with it
+func restoreFolder(tx *sql.Tx, id int) error {
+	var parentID, deletedAt sql.NullInt64
+	err := tx.QueryRow(`SELECT parent_id, deleted_at FROM folders WHERE id = ?`, id).Scan(&parentID, &deletedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("folder %d not found", id)
+	}
+	if err != nil || !deletedAt.Valid {
+		return err
+	}
+
+	_, err = tx.Exec(subtreeCTE+`
+		UPDATE folders SET deleted_at = NULL WHERE id IN (SELECT id FROM subtree) AND deleted_at = ?
+	`, id, deletedAt.Int64)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(subtreeCTE+`
+		UPDATE bookmarks SET deleted_at = NULL WHERE folder_id IN (SELECT id FROM subtree) AND deleted_at = ?
+	`, id, deletedAt.Int64)
+	if err != nil {
+		return err
+	}
+
+	if parentID.Valid {
+		exists, err := restoreFolderChain(tx, int(parentID.Int64))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			_, err = tx.Exec(`UPDATE folders SET parent_id = NULL WHERE id = ?`, id)
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreFolderChain takes a folder and all its ancestors out of the trash,
+// without restoring their other contents. Returns false if the folder no
+// longer exists.
+func restoreFolderChain(tx *sql.Tx, folderID int) (bool, error) {
+	var count int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM folders WHERE id = ?`, folderID).Scan(&count); err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ancestorsCTE+`UPDATE folders SET deleted_at = NULL WHERE id IN (SELECT id FROM chain)`, folderID); err != nil {
+		return false, err
+	}
+	// An ancestor may have been purged, attach the topmost remaining one to the root
+	_, err := tx.Exec(ancestorsCTE+`
+		UPDATE folders SET parent_id = NULL
+		WHERE id IN (SELECT id FROM chain)
+			AND parent_id IS NOT NULL
+			AND parent_id NOT IN (SELECT id FROM folders)
+	`, folderID)
+	return true, err
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+// newFolder creates a folder and returns its ID
+func newFolder(t *testing.T, repo *SQLiteRepository, name string, parentID *int) int {
+	t.Helper()
+	f, err := repo.Folders().Create(name, parentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.ID
+}
+
+// newBookmark creates a bookmark titled title in folderID and returns its ID
+func newBookmark(t *testing.T, repo *SQLiteRepository, title string, folderID *int, tags ...string) int {
+	t.Helper()
+	b := &models.Bookmark{Title: title, URL: "https://" + title + ".example/", FolderID: folderID, Tags: tags}
+	if err := repo.Bookmarks().Create(b); err != nil {
+		t.Fatal(err)
+	}
+	return b.ID
+}
+
+// trashedAt sets when a row of table was trashed
+func trashedAt(t *testing.T, repo *SQLiteRepository, table string, id int, at time.Time) {
+	t.Helper()
+	if _, err := repo.db.Exec(`UPDATE `+table+` SET deleted_at = ? WHERE id = ?`, at.Unix(), id); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// bookmarkAt returns the bookmark id, including a trashed one, and fails
+// the test if it doesn't exist
+func bookmarkAt(t *testing.T, repo *SQLiteRepository, id int) *models.Bookmark {
+	t.Helper()
+	b, err := repo.Bookmarks().GetByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b == nil {
+		t.Fatalf("bookmark %d not found", id)
+	}
+	return b
+}
+
+// folderAt is bookmarkAt for folders
+func folderAt(t *testing.T, repo *SQLiteRepository, id int) *models.Folder {
+	t.Helper()
+	f, err := repo.Folders().GetByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f == nil {
+		t.Fatalf("folder %d not found", id)
+	}
+	return f
+}
+
+func TestTrashRestoreBookmarkInTrashedFolder(t *testing.T) {
+	repo := openRepo(t, createDB(t, ""))
+	work := newFolder(t, repo, "Work", nil)
+	projects := newFolder(t, repo, "Projects", &work)
+	restored := newBookmark(t, repo, "restored", &projects)
+	sibling := newBookmark(t, repo, "sibling", &projects)
+	other := newBookmark(t, repo, "other", &work)
+	if err := repo.Folders().Delete(work, models.FolderDeleteCascade); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Trash().Restore(models.ItemTypeBookmark, restored); err != nil {
+		t.Fatal(err)
+	}
+
+	// The folders above the bookmark come back, the rest of their contents don't
+	b := bookmarkAt(t, repo, restored)
+	if !b.DeletedAt.IsZero() || !sameIntPtr(b.FolderID, &projects) {
+		t.Errorf("restored bookmark: folder %v, deleted at %v; want it live in Projects", fmtIntPtr(b.FolderID), b.DeletedAt)
+	}
+	for _, id := range []int{work, projects} {
+		if f := folderAt(t, repo, id); !f.DeletedAt.IsZero() {
+			t.Errorf("folder %s is still in the trash", f.Name)
+		}
+	}
+	if p := folderAt(t, repo, projects); !sameIntPtr(p.ParentID, &work) {
+		t.Errorf("Projects parent = %v, want Work", fmtIntPtr(p.ParentID))
+	}
+	for _, id := range []int{sibling, other} {
+		if b := bookmarkAt(t, repo, id); b.DeletedAt.IsZero() {
+			t.Errorf("bookmark %s was restored too", b.Title)
+		}
+	}
+}
+
+func TestTrashPurgeFolder(t *testing.T) {
+	repo := openRepo(t, createDB(t, ""))
+	work := newFolder(t, repo, "Work", nil)
+	projects := newFolder(t, repo, "Projects", &work)
+	deep := newFolder(t, repo, "Deep", &projects)
+	bookmarks := []int{
+		newBookmark(t, repo, "work", &work),
+		newBookmark(t, repo, "projects", &projects, "purged"),
+		newBookmark(t, repo, "deep", &deep),
+	}
+	live := newFolder(t, repo, "Live", nil)
+	kept := newBookmark(t, repo, "kept", &live, "kept")
+
+	// A folder that is not in the trash can't be purged
+	if err := repo.Trash().Purge(models.ItemTypeFolder, work); err != nil {
+		t.Fatal(err)
+	}
+	folderAt(t, repo, work)
+
+	if err := repo.Folders().Delete(work, models.FolderDeleteCascade); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Trash().Purge(models.ItemTypeFolder, work); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []int{work, projects, deep} {
+		if f, err := repo.Folders().GetByID(id); err != nil || f != nil {
+			t.Errorf("folder %d after the purge: %+v, %v", id, f, err)
+		}
+	}
+	for _, id := range bookmarks {
+		if b, err := repo.Bookmarks().GetByID(id); err != nil || b != nil {
+			t.Errorf("bookmark %d after the purge: %+v, %v", id, b, err)
+		}
+	}
+	if f := folderAt(t, repo, live); !f.DeletedAt.IsZero() {
+		t.Error("folder Live was trashed")
+	}
+	if b := bookmarkAt(t, repo, kept); !b.DeletedAt.IsZero() {
+		t.Error("bookmark kept was trashed")
+	}
+	// Tags of purged bookmarks only are gone
+	tags, err := repo.Bookmarks().ListTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Name != "kept" {
+		t.Errorf("tags after the purge = %+v, want only kept", tags)
+	}
+}
+
+func TestTrashEmpty(t *testing.T) {
+	repo := openRepo(t, createDB(t, ""))
+	before := time.Now().Add(-time.Hour)
+	old := before.Add(-24 * time.Hour)
+
+	live := newFolder(t, repo, "Live", nil)
+	liveBookmarks := []int{newBookmark(t, repo, "live", &live), newBookmark(t, repo, "root", nil)}
+
+	oldFolder := newFolder(t, repo, "Old", nil)
+	oldSub := newFolder(t, repo, "Sub", &oldFolder)
+	inOldFolder := newBookmark(t, repo, "in-old", &oldSub)
+	if err := repo.Folders().Delete(oldFolder, models.FolderDeleteCascade); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{oldFolder, oldSub} {
+		trashedAt(t, repo, "folders", id, old)
+	}
+	trashedAt(t, repo, "bookmarks", inOldFolder, old)
+
+	oldBookmark := newBookmark(t, repo, "old", &live)
+	trashedAt(t, repo, "bookmarks", oldBookmark, old)
+	recent := newBookmark(t, repo, "recent", &live)
+	if err := repo.Bookmarks().Delete(recent); err != nil {
+		t.Fatal(err)
+	}
+
+	bookmarks, folders, err := repo.Trash().Empty(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bookmarks != 2 || folders != 2 {
+		t.Errorf("Empty = %d bookmarks, %d folders, want 2 and 2", bookmarks, folders)
+	}
+
+	for _, id := range []int{oldFolder, oldSub} {
+		if f, err := repo.Folders().GetByID(id); err != nil || f != nil {
+			t.Errorf("folder %d after Empty: %+v, %v", id, f, err)
+		}
+	}
+	for _, id := range []int{inOldFolder, oldBookmark} {
+		if b, err := repo.Bookmarks().GetByID(id); err != nil || b != nil {
+			t.Errorf("bookmark %d after Empty: %+v, %v", id, b, err)
+		}
+	}
+	// Live rows and those trashed after before are left alone
+	if f := folderAt(t, repo, live); !f.DeletedAt.IsZero() {
+		t.Error("folder Live was trashed")
+	}
+	for _, id := range liveBookmarks {
+		if b := bookmarkAt(t, repo, id); !b.DeletedAt.IsZero() {
+			t.Errorf("bookmark %s was trashed", b.Title)
+		}
+	}
+	if b := bookmarkAt(t, repo, recent); b.DeletedAt.IsZero() {
+		t.Error("bookmark recent was restored")
+	}
+}
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/dastanaron/bookmarks/internal/models"
 	"github.com/dastanaron/bookmarks/internal/repository"
@@ -22,27 +24,9 @@ func (s *BookmarkService) ListAll() ([]models.Bookmark, error) {
 	return s.repo.Bookmarks().List()
 }
 
-// Search filters bookmarks by query string
+// Search returns bookmarks matching the query string, best matches first
 func (s *BookmarkService) Search(query string) ([]models.Bookmark, error) {
-	all, err := s.repo.Bookmarks().List()
-	if err != nil {
-		return nil, err
-	}
-
-	if query == "" {
-		return all, nil
-	}
-
-	queryLower := strings.ToLower(query)
-	var filtered []models.Bookmark
-	for _, b := range all {
-		if strings.Contains(strings.ToLower(b.Title), queryLower) ||
-			strings.Contains(strings.ToLower(b.URL), queryLower) ||
-			strings.Contains(strings.ToLower(b.Description), queryLower) {
-			filtered = append(filtered, b)
-		}
-	}
-	return filtered, nil
+	return s.repo.Bookmarks().Search(query, nil, 0, 0)
 }
 
 // GetByFolderID returns bookmarks in a specific folder
@@ -70,34 +54,15 @@ func (s *BookmarkService) GetByFolderID(folderID *int) ([]models.Bookmark, error
 	return filtered, nil
 }
 
-// SearchInFolder filters bookmarks by query string within a specific folder
+// SearchInFolder returns bookmarks matching the query string within a specific folder,
+// or within all folders if folderID is nil
 func (s *BookmarkService) SearchInFolder(query string, folderID *int) ([]models.Bookmark, error) {
-	var all []models.Bookmark
-	var err error
-
-	if folderID != nil {
-		all, err = s.GetByFolderID(folderID)
-	} else {
-		all, err = s.ListAll()
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	if query == "" {
-		return all, nil
-	}
+	return s.repo.Bookmarks().Search(query, folderID, 0, 0)
+}
 
-	queryLower := strings.ToLower(query)
-	var filtered []models.Bookmark
-	for _, b := range all {
-		if strings.Contains(strings.ToLower(b.Title), queryLower) ||
-			strings.Contains(strings.ToLower(b.URL), queryLower) ||
-			strings.Contains(strings.ToLower(b.Description), queryLower) {
-			filtered = append(filtered, b)
-		}
-	}
-	return filtered, nil
+// SearchPage is SearchInFolder with paging, a limit of 0 means no limit
+func (s *BookmarkService) SearchPage(query string, folderID *int, limit, offset int) ([]models.Bookmark, error) {
+	return s.repo.Bookmarks().Search(query, folderID, limit, offset)
 }
 
 // GetByID returns a bookmark by ID
@@ -110,6 +75,11 @@ func (s *BookmarkService) GetByURL(url string) (*models.Bookmark, error) {
 	return s.repo.Bookmarks().GetByURL(url)
 }
 
+// ListByURL returns all bookmarks with the URL, oldest first
+func (s *BookmarkService) ListByURL(url string) ([]models.Bookmark, error) {
+	return s.repo.Bookmarks().ListByURL(url)
+}
+
 // Create creates a new bookmark
 func (s *BookmarkService) Create(b *models.Bookmark) error {
 	return s.repo.Bookmarks().Create(b)
@@ -120,17 +90,203 @@ func (s *BookmarkService) Update(b *models.Bookmark) error {
 	return s.repo.Bookmarks().Update(b)
 }
 
+// Replace updates an existing bookmark, keeping a non-zero b.UpdatedAt
+func (s *BookmarkService) Replace(b *models.Bookmark) error {
+	return s.repo.Bookmarks().Replace(b)
+}
+
 // Upsert creates a new bookmark if URL doesn't exist, otherwise updates the existing one.
 // Returns true if created, false if updated.
 func (s *BookmarkService) Upsert(b *models.Bookmark) (bool, error) {
 	return s.repo.Bookmarks().Upsert(b)
 }
 
-// Delete deletes a bookmark by ID
+// Delete moves a bookmark to the trash
 func (s *BookmarkService) Delete(id int) error {
 	return s.repo.Bookmarks().Delete(id)
 }
 
+// MarkVisited records that a bookmark was opened
+func (s *BookmarkService) MarkVisited(id int) error {
+	return s.repo.Bookmarks().MarkVisited(id)
+}
+
+// AddTags attaches tags to a bookmark
+func (s *BookmarkService) AddTags(bookmarkID int, tags []string) error {
+	return s.repo.Bookmarks().AddTags(bookmarkID, tags)
+}
+
+// RemoveTags detaches tags from a bookmark
+func (s *BookmarkService) RemoveTags(bookmarkID int, tags []string) error {
+	return s.repo.Bookmarks().RemoveTags(bookmarkID, tags)
+}
+
+// ListTags returns all tags with their bookmark counts
+func (s *BookmarkService) ListTags() ([]models.Tag, error) {
+	return s.repo.Bookmarks().ListTags()
+}
+
+// RenameTag renames a tag
+func (s *BookmarkService) RenameTag(oldName, newName string) error {
+	return s.repo.Bookmarks().RenameTag(oldName, newName)
+}
+
+// MergeTags merges the source tags into target
+func (s *BookmarkService) MergeTags(sources []string, target string) error {
+	return s.repo.Bookmarks().MergeTags(sources, target)
+}
+
+// ListByTags returns bookmarks having all (matchAll) or any of the given tags
+func (s *BookmarkService) ListByTags(tags []string, matchAll bool) ([]models.Bookmark, error) {
+	return s.repo.Bookmarks().ListByTags(tags, matchAll)
+}
+
+// ParseTags splits a comma-separated tag list ("go, databases") into tag names
+func ParseTags(s string) []string {
+	tags := []string{}
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// ParseImportConflictPolicy parses an import conflict policy name such as
+// "merge-fields" (see models.ImportConflictPolicyNames)
+func ParseImportConflictPolicy(s string) (models.ImportConflictPolicy, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for i, n := range models.ImportConflictPolicyNames {
+		if n == name {
+			return models.ImportConflictPolicy(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown conflict policy %q (expected one of: %s)",
+		s, strings.Join(models.ImportConflictPolicyNames, ", "))
+}
+
+// ChangedFields returns the names of the fields ("title", "description",
+// "folder", "keyword", "icon", "read later", "tags") that saving b over cur
+// would change. Timestamps are not compared. A keyword, icon or tags that b
+// leaves unset are kept when saving, so they don't count.
+func ChangedFields(cur, b *models.Bookmark) []string {
+	var fields []string
+	if cur.Title != b.Title {
+		fields = append(fields, "title")
+	}
+	if cur.Description != b.Description {
+		fields = append(fields, "description")
+	}
+	if !sameFolder(cur.FolderID, b.FolderID) {
+		fields = append(fields, "folder")
+	}
+	if b.Keyword != "" && b.Keyword != cur.Keyword {
+		fields = append(fields, "keyword")
+	}
+	if b.Icon != nil && (cur.Icon == nil || *cur.Icon != *b.Icon) {
+		fields = append(fields, "icon")
+	}
+	if b.ReadLater != cur.ReadLater {
+		fields = append(fields, "read later")
+	}
+	if b.Tags != nil && !sameTags(cur.Tags, b.Tags) {
+		fields = append(fields, "tags")
+	}
+	return fields
+}
+
+// MergeBookmark returns cur with its empty fields filled in from b and the
+// tags of both. A title that is just the URL counts as empty. The folder is
+// kept, the visit time is the later one.
+func MergeBookmark(cur, b models.Bookmark) models.Bookmark {
+	merged := cur
+	if merged.Title == "" || merged.Title == merged.URL {
+		if b.Title != "" {
+			merged.Title = b.Title
+		}
+	}
+	if merged.Description == "" {
+		merged.Description = b.Description
+	}
+	if merged.Keyword == "" {
+		merged.Keyword = b.Keyword
+	}
+	if merged.Icon == nil {
+		merged.Icon = b.Icon
+	}
+	merged.ReadLater = merged.ReadLater || b.ReadLater
+	merged.Tags = append([]string{}, cur.Tags...)
+	for _, t := range b.Tags {
+		if !containsTag(merged.Tags, t) {
+			merged.Tags = append(merged.Tags, t)
+		}
+	}
+	if merged.CreatedAt.IsZero() {
+		merged.CreatedAt = b.CreatedAt
+	}
+	if b.LastVisitedAt.After(merged.LastVisitedAt) {
+		merged.LastVisitedAt = b.LastVisitedAt
+	}
+	return merged
+}
+
+// OverwriteBookmark returns b with the keyword and icon that it leaves unset
+// taken from cur, so that saving it over cur changes only the fields
+// reported by ChangedFields. Tags are kept by leaving them nil.
+func OverwriteBookmark(cur, b models.Bookmark) models.Bookmark {
+	if b.Keyword == "" {
+		b.Keyword = cur.Keyword
+	}
+	if b.Icon == nil {
+		b.Icon = cur.Icon
+	}
+	return b
+}
+
+// IsNewer reports whether b was modified after cur, using the creation time
+// if the modification time is unknown. A bookmark without timestamps is
+// never newer.
+func IsNewer(b, cur *models.Bookmark) bool {
+	modified := func(x *models.Bookmark) time.Time {
+		if x.UpdatedAt.IsZero() {
+			return x.CreatedAt
+		}
+		return x.UpdatedAt
+	}
+	t := modified(b)
+	return !t.IsZero() && t.After(modified(cur))
+}
+
+func sameFolder(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// sameTags compares two tag lists ignoring order and case
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, t := range b {
+		if !containsTag(a, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsTag reports whether tags contains tag, ignoring case
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // FolderService provides business logic for folders
 type FolderService struct {
 	repo repository.Repository
@@ -156,19 +312,179 @@ func (s *FolderService) Create(name string, parentID *int) (*models.Folder, erro
 	return s.repo.Folders().Create(name, parentID)
 }
 
+// Find returns the folder with the given name in the folder parentID
+// (nil for the root), or nil if there is none
+func (s *FolderService) Find(name string, parentID *int) (*models.Folder, error) {
+	return s.repo.Folders().Find(name, parentID)
+}
+
+// Insert creates a folder with the ID (if non-zero) and timestamps of f
+func (s *FolderService) Insert(f *models.Folder) error {
+	return s.repo.Folders().Insert(f)
+}
+
 // Upsert creates or returns existing folder
 func (s *FolderService) Upsert(name string, parentID *int) (*models.Folder, error) {
 	return s.repo.Folders().Upsert(name, parentID)
 }
 
+// UpsertPath returns the folder at a slash-separated path such as
+// "Work/Projects/Go", creating missing folders along the way.
+// An empty path means the root and returns nil.
+func (s *FolderService) UpsertPath(path string) (*models.Folder, error) {
+	var folder *models.Folder
+	for _, name := range strings.Split(path, "/") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		var parentID *int
+		if folder != nil {
+			parentID = &folder.ID
+		}
+		f, err := s.repo.Folders().Upsert(name, parentID)
+		if err != nil {
+			return nil, fmt.Errorf("folder %q: %w", name, err)
+		}
+		folder = f
+	}
+	return folder, nil
+}
+
+// FindPath returns the folder at a slash-separated path such as "Work/Projects",
+// or nil if it doesn't exist. An empty path means the root and returns nil.
+func (s *FolderService) FindPath(path string) (*models.Folder, error) {
+	folders, err := s.repo.Folders().List()
+	if err != nil {
+		return nil, err
+	}
+
+	var current *models.Folder
+	for _, name := range strings.Split(path, "/") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		var next *models.Folder
+		for i := range folders {
+			f := &folders[i]
+			if f.Name != name {
+				continue
+			}
+			if (current == nil && f.ParentID == nil) || (current != nil && f.ParentID != nil && *f.ParentID == current.ID) {
+				next = f
+				break
+			}
+		}
+		if next == nil {
+			return nil, nil
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// Paths returns the slash-separated path of every folder, keyed by folder ID
+func (s *FolderService) Paths() (map[int]string, error) {
+	folders, err := s.repo.Folders().List()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*models.Folder, len(folders))
+	for i := range folders {
+		byID[folders[i].ID] = &folders[i]
+	}
+
+	paths := make(map[int]string, len(folders))
+	var pathOf func(id int, depth int) string
+	pathOf = func(id int, depth int) string {
+		if p, ok := paths[id]; ok {
+			return p
+		}
+		f := byID[id]
+		if f == nil {
+			return ""
+		}
+		p := f.Name
+		// depth guards against parent cycles
+		if f.ParentID != nil && depth < len(folders) {
+			if parent := pathOf(*f.ParentID, depth+1); parent != "" {
+				p = parent + "/" + p
+			}
+		}
+		paths[id] = p
+		return p
+	}
+	for _, f := range folders {
+		pathOf(f.ID, 0)
+	}
+	return paths, nil
+}
+
+// Tree returns all folders and bookmarks, except trashed ones, as a tree.
+// Subfolders are sorted by name and bookmarks by title. Folders and
+// bookmarks whose parent is missing are put in the root.
+func (s *FolderService) Tree() (*models.FolderNode, error) {
+	folders, err := s.repo.Folders().List()
+	if err != nil {
+		return nil, err
+	}
+	bookmarks, err := s.repo.Bookmarks().List()
+	if err != nil {
+		return nil, err
+	}
+
+	root := &models.FolderNode{}
+	nodes := make(map[int]*models.FolderNode, len(folders))
+	for _, f := range folders {
+		nodes[f.ID] = &models.FolderNode{Folder: f}
+	}
+	parentOf := func(id *int) *models.FolderNode {
+		if id != nil {
+			if n := nodes[*id]; n != nil {
+				return n
+			}
+		}
+		return root
+	}
+	for _, f := range folders {
+		parent := parentOf(f.ParentID)
+		parent.Folders = append(parent.Folders, nodes[f.ID])
+	}
+	for _, b := range bookmarks {
+		parent := parentOf(b.FolderID)
+		parent.Bookmarks = append(parent.Bookmarks, b)
+	}
+	return root, nil
+}
+
 // Update updates an existing folder
 func (s *FolderService) Update(f *models.Folder) error {
 	return s.repo.Folders().Update(f)
 }
 
-// Delete deletes a folder by ID
-func (s *FolderService) Delete(id int) error {
-	return s.repo.Folders().Delete(id)
+// Delete moves a folder to the trash. Its subfolders and bookmarks are either
+// trashed with it or moved to the parent folder, depending on policy.
+func (s *FolderService) Delete(id int, policy models.FolderDeletePolicy) error {
+	return s.repo.Folders().Delete(id, policy)
+}
+
+// CountContents returns the number of bookmarks and subfolders (at any depth) in a folder
+func (s *FolderService) CountContents(id int) (bookmarks int, folders int, err error) {
+	return s.repo.Folders().CountContents(id)
+}
+
+// ParseFolderDeletePolicy parses a folder delete policy name: "move" or "cascade"
+func ParseFolderDeletePolicy(s string) (models.FolderDeletePolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "move":
+		return models.FolderDeleteMoveToParent, nil
+	case "cascade":
+		return models.FolderDeleteCascade, nil
+	default:
+		return 0, fmt.Errorf("unknown delete policy %q (expected move or cascade)", s)
+	}
 }
 
 // GetFolderContent returns all items (bookmarks and subfolders) in a folder
@@ -176,3 +492,34 @@ func (s *FolderService) Delete(id int) error {
 func (s *FolderService) GetFolderContent(folderID *int) ([]models.Item, error) {
 	return s.repo.Folders().GetFolderContent(folderID)
 }
+
+// TrashService provides business logic for deleted bookmarks and folders
+type TrashService struct {
+	repo repository.Repository
+}
+
+// NewTrashService creates a new trash service
+func NewTrashService(repo repository.Repository) *TrashService {
+	return &TrashService{repo: repo}
+}
+
+// List returns trashed items, most recently deleted first
+func (s *TrashService) List() ([]models.Item, error) {
+	return s.repo.Trash().List()
+}
+
+// Restore takes an item out of the trash into its original folder
+func (s *TrashService) Restore(item *models.Item) error {
+	return s.repo.Trash().Restore(item.Type, item.ID)
+}
+
+// Purge permanently deletes a trashed item
+func (s *TrashService) Purge(item *models.Item) error {
+	return s.repo.Trash().Purge(item.Type, item.ID)
+}
+
+// Empty permanently deletes items that have been in the trash longer than olderThan.
+// An olderThan of 0 empties the whole trash.
+func (s *TrashService) Empty(olderThan time.Duration) (bookmarks int, folders int, err error) {
+	return s.repo.Trash().Empty(time.Now().Add(-olderThan))
+}
@@ -0,0 +1,81 @@
+// Client-side search over window.BOOKMARKS_INDEX (assets/search-index.js).
+// Every word of the query must appear in the title, URL, description, tags
+// or folder of a bookmark.
+(function () {
+  "use strict";
+
+  var index = window.BOOKMARKS_INDEX || [];
+  var input = document.getElementById("search");
+  var results = document.getElementById("results");
+  var content = document.getElementById("content");
+  var maxResults = 200;
+
+  // Only link to URLs that can't run script in the page
+  function safeURL(url) {
+    return /^(https?|ftp|file|mailto):/i.test(url) ? url : null;
+  }
+
+  function text(tag, className, value) {
+    var el = document.createElement(tag);
+    if (className) {
+      el.className = className;
+    }
+    el.textContent = value;
+    return el;
+  }
+
+  function haystack(entry) {
+    return [entry.t, entry.u, entry.d || "", (entry.g || []).join(" "), entry.f]
+      .join("\n").toLowerCase();
+  }
+
+  function search(query) {
+    var words = query.toLowerCase().split(/\s+/).filter(Boolean);
+    return index.filter(function (entry) {
+      var h = entry.h || (entry.h = haystack(entry));
+      return words.every(function (w) { return h.indexOf(w) >= 0; });
+    });
+  }
+
+  function render(matches) {
+    results.textContent = "";
+    results.appendChild(text("h1", null, matches.length + (matches.length === 1 ? " result" : " results")));
+
+    var list = document.createElement("ul");
+    list.className = "bookmarks";
+    matches.slice(0, maxResults).forEach(function (entry) {
+      var li = document.createElement("li");
+      var url = safeURL(entry.u);
+      var a = text(url ? "a" : "span", null, entry.t || entry.u);
+      if (url) {
+        a.href = url;
+      }
+      li.appendChild(a);
+      (entry.g || []).forEach(function (tag) {
+        li.appendChild(document.createTextNode(" "));
+        li.appendChild(text("span", "tag", tag));
+      });
+      li.appendChild(document.createTextNode(" "));
+      var folder = text("a", "folder", entry.f || "/");
+      folder.href = entry.p;
+      li.appendChild(folder);
+      if (entry.d) {
+        li.appendChild(text("p", "description", entry.d));
+      }
+      list.appendChild(li);
+    });
+    results.appendChild(list);
+  }
+
+  input.addEventListener("input", function () {
+    var query = input.value.trim();
+    if (query === "") {
+      results.hidden = true;
+      content.hidden = false;
+      return;
+    }
+    render(search(query));
+    results.hidden = false;
+    content.hidden = true;
+  });
+})();
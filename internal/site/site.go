@@ -0,0 +1,258 @@
+// Package site generates a static, browsable HTML site from a bookmark tree.
+// The output is a plain directory without absolute links or server-side
+// parts, so it also works when opened from file://.
+package site
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+//go:embed assets
+var assetFiles embed.FS
+
+// templates are parsed once; their data is the same for every site
+var templates = template.Must(template.ParseFS(templateFiles, "templates/*.html"))
+
+// Options configures the generated site
+type Options struct {
+	Title string // shown on every page; "Bookmarks" if empty
+}
+
+// link is a link to a folder page
+type link struct {
+	Name  string
+	Href  string
+	Count int // bookmarks in the folder and its subfolders
+}
+
+// treeNode is a folder in the folder tree on the index page
+type treeNode struct {
+	link
+	Children []treeNode
+}
+
+// bookmarkView is a bookmark as shown on a folder page
+type bookmarkView struct {
+	Title       string
+	URL         string
+	Description string
+	Tags        []string
+	Icon        template.URL // empty if the bookmark has no usable icon
+}
+
+// page is the data of a page template
+type page struct {
+	SiteTitle   string
+	Title       string
+	Breadcrumbs []link // ancestors of the folder, starting at the index
+	Folders     []link
+	Bookmarks   []bookmarkView
+	Tree        []treeNode // only set on the index page
+}
+
+// indexEntry is a bookmark in the search index. Keys are short because
+// the index holds every bookmark of the site.
+type indexEntry struct {
+	Title       string   `json:"t"`
+	URL         string   `json:"u"`
+	Description string   `json:"d,omitempty"`
+	Tags        []string `json:"g,omitempty"`
+	Folder      string   `json:"f"` // folder path
+	Page        string   `json:"p"` // file name of the folder page
+}
+
+// generator holds the state of one Generate call
+type generator struct {
+	dir   string
+	title string
+	index []indexEntry
+}
+
+// Generate writes a static site for the folder tree below root into dir,
+// creating it if needed: index.html with the folder tree and the bookmarks
+// of root, one folder-<id>.html page per folder, and the stylesheet, search
+// script and search index under assets/. Existing files are overwritten.
+func Generate(dir string, root *models.FolderNode, opts Options) error {
+	g := &generator{dir: dir, title: opts.Title}
+	if g.title == "" {
+		g.title = "Bookmarks"
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0755); err != nil {
+		return err
+	}
+	if err := g.writeAssets(); err != nil {
+		return err
+	}
+
+	home := link{Name: g.title, Href: "index.html"}
+	index := g.folderPage(root, g.title, nil)
+	index.Tree = folderTree(root)
+	if err := g.writePage("index.html", index); err != nil {
+		return err
+	}
+	// Folder paths in the search index start at the published folder; the
+	// root of the whole tree has no name
+	g.addToIndex(root, root.Folder.Name, home.Href)
+
+	for _, child := range root.Folders {
+		if err := g.writeFolder(child, joinPath(root.Folder.Name, child.Folder.Name), []link{home}); err != nil {
+			return err
+		}
+	}
+	return g.writeIndex()
+}
+
+// writeFolder writes the page of a folder and of its subfolders
+func (g *generator) writeFolder(node *models.FolderNode, path string, breadcrumbs []link) error {
+	name := pageName(node)
+	if err := g.writePage(name, g.folderPage(node, node.Folder.Name, breadcrumbs)); err != nil {
+		return err
+	}
+	g.addToIndex(node, path, name)
+
+	// Copy so that siblings don't share the backing array
+	crumbs := append(append([]link{}, breadcrumbs...), link{Name: node.Folder.Name, Href: name})
+	for _, child := range node.Folders {
+		if err := g.writeFolder(child, joinPath(path, child.Folder.Name), crumbs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// folderPage returns the page data of a folder
+func (g *generator) folderPage(node *models.FolderNode, title string, breadcrumbs []link) page {
+	p := page{SiteTitle: g.title, Title: title, Breadcrumbs: breadcrumbs}
+	for _, child := range node.Folders {
+		p.Folders = append(p.Folders, folderLink(child))
+	}
+	for _, b := range node.Bookmarks {
+		p.Bookmarks = append(p.Bookmarks, bookmarkView{
+			Title:       b.Title,
+			URL:         b.URL,
+			Description: b.Description,
+			Tags:        b.Tags,
+			Icon:        iconURL(b.Icon),
+		})
+	}
+	return p
+}
+
+// writePage renders the page template into a file
+func (g *generator) writePage(name string, p page) error {
+	file, err := os.Create(filepath.Join(g.dir, name))
+	if err != nil {
+		return err
+	}
+	if err := templates.ExecuteTemplate(file, "page.html", p); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return file.Close()
+}
+
+// addToIndex adds the bookmarks of a folder to the search index
+func (g *generator) addToIndex(node *models.FolderNode, path, pageName string) {
+	for _, b := range node.Bookmarks {
+		g.index = append(g.index, indexEntry{
+			Title:       b.Title,
+			URL:         b.URL,
+			Description: b.Description,
+			Tags:        b.Tags,
+			Folder:      path,
+			Page:        pageName,
+		})
+	}
+}
+
+// writeIndex writes the search index. Browsers don't let pages opened from
+// file:// fetch other files, so the JSON is wrapped in a script that the
+// pages load with a <script> tag.
+func (g *generator) writeIndex() error {
+	if g.index == nil {
+		g.index = []indexEntry{}
+	}
+	// json.Marshal escapes <, > and &, so the data can't end the script
+	data, err := json.Marshal(g.index)
+	if err != nil {
+		return err
+	}
+	script := "window.BOOKMARKS_INDEX = " + string(data) + ";\n"
+	return os.WriteFile(filepath.Join(g.dir, "assets", "search-index.js"), []byte(script), 0644)
+}
+
+// writeAssets copies the stylesheet and the search script
+func (g *generator) writeAssets() error {
+	return fs.WalkDir(assetFiles, "assets", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := assetFiles.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(g.dir, filepath.FromSlash(path)), data, 0644)
+	})
+}
+
+// joinPath appends a folder name to a slash-separated folder path
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "/" + name
+}
+
+// folderTree returns the folder tree below node for the index page
+func folderTree(node *models.FolderNode) []treeNode {
+	var tree []treeNode
+	for _, child := range node.Folders {
+		tree = append(tree, treeNode{link: folderLink(child), Children: folderTree(child)})
+	}
+	return tree
+}
+
+// folderLink returns the link to the page of a folder
+func folderLink(node *models.FolderNode) link {
+	_, count := node.Count()
+	return link{Name: node.Folder.Name, Href: pageName(node), Count: count}
+}
+
+// pageName returns the file name of the page of a folder. Folder IDs keep
+// the names short, safe on every file system and stable between runs.
+func pageName(node *models.FolderNode) string {
+	return fmt.Sprintf("folder-%d.html", node.Folder.ID)
+}
+
+// iconURL returns a stored icon as an image data URL, which html/template
+// would otherwise reject as unsafe. Icons are stored as data URLs, or as
+// plain base64 by older versions.
+func iconURL(icon *string) template.URL {
+	if icon == nil || *icon == "" {
+		return ""
+	}
+	s := strings.TrimSpace(*icon)
+	if strings.HasPrefix(s, "data:image/") {
+		if strings.ContainsAny(s, "\"'<> ") {
+			return ""
+		}
+		return template.URL(s)
+	}
+	if strings.Contains(s, ":") || strings.ContainsAny(s, "\"'<> ") {
+		return "" // a remote URL; the site must not depend on the network
+	}
+	return template.URL("data:image/png;base64," + s)
+}
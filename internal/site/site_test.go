@@ -0,0 +1,147 @@
+package site
+
+import (
+	"encoding/json"
+	"html/template"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dastanaron/bookmarks/internal/models"
+)
+
+func TestIconURL(t *testing.T) {
+	tests := []struct {
+		icon string
+		want template.URL
+	}{
+		{icon: "", want: ""},
+		{icon: "data:image/png;base64,aWNvbg==", want: "data:image/png;base64,aWNvbg=="},
+		{icon: " aWNvbg==\n", want: "data:image/png;base64,aWNvbg=="}, // plain base64 of older versions
+		{icon: "javascript:alert(1)", want: ""},
+		{icon: "https://example.com/favicon.ico", want: ""},
+		{icon: `data:image/svg+xml,<svg onload="alert(1)">`, want: ""},
+		{icon: `aWNvbg=="><script>`, want: ""},
+	}
+	for _, tt := range tests {
+		icon := tt.icon
+		if got := iconURL(&icon); got != tt.want {
+			t.Errorf("iconURL(%q) = %q, want %q", tt.icon, got, tt.want)
+		}
+	}
+	if got := iconURL(nil); got != "" {
+		t.Errorf("iconURL(nil) = %q, want empty", got)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	const script = `<script>alert("x")</script>`
+	icon := func(s string) *string { return &s }
+
+	root := &models.FolderNode{}
+	root.Bookmarks = []models.Bookmark{{Title: "Root " + script, URL: "https://root.example/"}}
+	folder := root.AddFolder("Folder " + script)
+	folder.Folder.ID = 7
+	folder.Bookmarks = []models.Bookmark{
+		{Title: "Data", URL: "https://data.example/", Icon: icon("data:image/png;base64,ZGF0YQ==")},
+		{Title: "Bare", URL: "https://bare.example/", Icon: icon("YmFyZQ==")},
+		{Title: "Script", URL: "https://script.example/", Icon: icon("javascript:alert(1)")},
+		{Title: "Remote", URL: "https://remote.example/", Icon: icon("https://remote.example/favicon.ico"), Tags: []string{script}},
+	}
+	sub := folder.AddFolder("Sub")
+	sub.Folder.ID = 8
+	sub.Bookmarks = []models.Bookmark{{Title: "Deep", URL: "https://deep.example/", Description: "</script><script>alert(1)//"}}
+
+	dir := t.TempDir()
+	if err := Generate(dir, root, Options{Title: "My " + script}); err != nil {
+		t.Fatal(err)
+	}
+
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	wantFiles := []string{
+		"assets/search-index.js", "assets/search.js", "assets/style.css",
+		"folder-7.html", "folder-8.html", "index.html",
+	}
+	if !reflect.DeepEqual(files, wantFiles) {
+		t.Errorf("files = %q, want %q", files, wantFiles)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// Names, titles, tags and descriptions are escaped on every page
+	for _, name := range []string{"index.html", "folder-7.html", "folder-8.html"} {
+		html := read(name)
+		if strings.Contains(html, "<script>alert") {
+			t.Errorf("%s contains an unescaped script", name)
+		}
+		if !strings.Contains(html, "&lt;script&gt;") {
+			t.Errorf("%s doesn't contain the escaped script", name)
+		}
+	}
+
+	// Only data URLs become icons, the network and scripts are never used
+	page := read("folder-7.html")
+	for _, want := range []string{`src="data:image/png;base64,ZGF0YQ=="`, `src="data:image/png;base64,YmFyZQ=="`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("folder-7.html doesn't contain %s", want)
+		}
+	}
+	if n := strings.Count(page, "<img"); n != 2 {
+		t.Errorf("folder-7.html has %d icons, want 2", n)
+	}
+	for _, unwanted := range []string{"javascript:", "favicon.ico"} {
+		if strings.Contains(page, unwanted) {
+			t.Errorf("folder-7.html contains %s", unwanted)
+		}
+	}
+
+	// The search index can't end its script, and holds every bookmark with its folder
+	index := read("assets/search-index.js")
+	if strings.ContainsAny(index, "<>") {
+		t.Errorf("search-index.js contains < or >:\n%s", index)
+	}
+	const prefix, suffix = "window.BOOKMARKS_INDEX = ", ";\n"
+	if !strings.HasPrefix(index, prefix) || !strings.HasSuffix(index, suffix) {
+		t.Fatalf("search-index.js = %q", index)
+	}
+	var entries []indexEntry
+	if err := json.Unmarshal([]byte(strings.TrimSuffix(strings.TrimPrefix(index, prefix), suffix)), &entries); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Title+"|"+e.Folder+"|"+e.Page)
+	}
+	want := []string{
+		"Root " + script + "||index.html",
+		"Data|Folder " + script + "|folder-7.html",
+		"Bare|Folder " + script + "|folder-7.html",
+		"Script|Folder " + script + "|folder-7.html",
+		"Remote|Folder " + script + "|folder-7.html",
+		"Deep|Folder " + script + "/Sub|folder-8.html",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("search index =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
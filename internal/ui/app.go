@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os/exec"
 	"runtime"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/dastanaron/bookmarks/internal/models"
 	"github.com/dastanaron/bookmarks/internal/service"
@@ -20,11 +22,29 @@ const (
 	ModeModal  = 4
 )
 
+// sortMode defines the order of items in the list
+type sortMode int
+
+const (
+	sortDefault sortMode = iota // folders by name, bookmarks by name or search relevance
+	sortCreated                 // newest first
+	sortUpdated                 // most recently updated first
+	sortVisited                 // most recently visited first
+)
+
+var sortModeNames = map[sortMode]string{
+	sortDefault: "name",
+	sortCreated: "created",
+	sortUpdated: "updated",
+	sortVisited: "visited",
+}
+
 // folderItem represents a folder item in the list
 type folderItem struct {
-	ID    *int // nil for "All Bookmarks"
+	ID    *int // nil for "All Bookmarks" and "Trash"
 	Name  string
-	Level int // nesting level (0 = root)
+	Level int  // nesting level (0 = root)
+	Trash bool // true for the "Trash" virtual folder
 }
 
 // App represents the TUI application
@@ -43,13 +63,16 @@ type App struct {
 	status         *tview.TextView
 	bookmarkSvc    *service.BookmarkService
 	folderSvc      *service.FolderService
+	trashSvc       *service.TrashService
 	selectedFolder *int         // ID of selected folder, nil = root folder
+	showTrash      bool         // true = item list shows the trash
 	focusOnFolders bool         // true = focus on folder list, false = on item list
 	folderItems    []folderItem // list of folders for quick access
+	sortMode       sortMode     // order of items in the list
 }
 
 // NewApp creates a new application instance
-func NewApp(bookmarkSvc *service.BookmarkService, folderSvc *service.FolderService) *App {
+func NewApp(bookmarkSvc *service.BookmarkService, folderSvc *service.FolderService, trashSvc *service.TrashService) *App {
 	return &App{
 		app:            tview.NewApplication(),
 		folderList:     tview.NewList(),
@@ -61,9 +84,11 @@ func NewApp(bookmarkSvc *service.BookmarkService, folderSvc *service.FolderServi
 		status:         tview.NewTextView().SetDynamicColors(true),
 		bookmarkSvc:    bookmarkSvc,
 		folderSvc:      folderSvc,
+		trashSvc:       trashSvc,
 		selectedFolder: nil, // By default show all bookmarks
 		focusOnFolders: false,
 		folderItems:    []folderItem{},
+		sortMode:       sortDefault,
 	}
 }
 
@@ -134,7 +159,10 @@ func (a *App) updateStatus() {
 		countText = " [::b]0[::r] items"
 	}
 
-	statusText := "[::b]Tab[::r] switch  [::b]/[::r] search  [::b]a[::r] add  [::b]e[::r] edit  [::b]d[::r] del  [::b]Enter[::r] open/select  [::b]q[::r] quit" + countText
+	statusText := "[::b]Tab[::r] switch  [::b]/[::r] search  [::b]a[::r] add  [::b]e[::r] edit  [::b]d[::r] del  [::b]l[::r] read later  [::b]s[::r] sort:" + sortModeNames[a.sortMode] + "  [::b]Enter[::r] open/select  [::b]q[::r] quit" + countText
+	if a.showTrash {
+		statusText = "[::b]Tab[::r] switch  [::b]/[::r] search  [::b]r[::r] restore  [::b]d[::r] delete forever  [::b]s[::r] sort:" + sortModeNames[a.sortMode] + "  [::b]q[::r] quit" + countText
+	}
 	if a.focusOnFolders {
 		statusText = "[::b]Tab[::r] switch  [::b]Enter[::r] select  [::b]a[::r] add folder  [::b]e[::r] edit folder  [::b]d[::r] del folder  [::b]q[::r] quit" + countText
 	}
@@ -148,8 +176,12 @@ func (a *App) reloadBookmarks() error {
 
 func (a *App) loadFolderContent() error {
 	var err error
-	// Get contents of selected folder (bookmarks and subfolders)
-	a.allItems, err = a.folderSvc.GetFolderContent(a.selectedFolder)
+	if a.showTrash {
+		a.allItems, err = a.trashSvc.List()
+	} else {
+		// Get contents of selected folder (bookmarks and subfolders)
+		a.allItems, err = a.folderSvc.GetFolderContent(a.selectedFolder)
+	}
 	if err != nil {
 		// On error show empty list
 		a.allItems = []models.Item{}
@@ -168,7 +200,9 @@ func (a *App) loadFolderContent() error {
 	}
 
 	// Update list title
-	if a.selectedFolder == nil {
+	if a.showTrash {
+		a.list.SetTitle("Items (Trash)")
+	} else if a.selectedFolder == nil {
 		a.list.SetTitle("Items (Root)")
 	} else {
 		folder, err := a.folderSvc.GetByID(*a.selectedFolder)
@@ -190,65 +224,66 @@ func (a *App) applyFilter(text string) {
 		return
 	}
 
-	// If folder not selected, search all bookmarks via SearchInFolder
-	if a.selectedFolder == nil {
-		bookmarks, err := a.bookmarkSvc.SearchInFolder(text, nil)
-		if err != nil {
-			a.items = []models.Item{}
-			a.fillList()
-			return
-		}
-
-		// Convert bookmarks to Items
+	// Trashed items are not in the search index, match them by name and URL
+	if a.showTrash {
+		textLower := strings.ToLower(text)
 		var items []models.Item
-		for _, b := range bookmarks {
-			item := models.Item{
-				Type:        models.ItemTypeBookmark,
-				ID:          b.ID,
-				Name:        b.Title,
-				URL:         &b.URL,
-				Description: &b.Description,
-				Icon:        b.Icon,
-				ParentID:    b.FolderID,
+		for _, item := range a.allItems {
+			url := ""
+			if item.URL != nil {
+				url = *item.URL
+			}
+			if strings.Contains(strings.ToLower(item.Name), textLower) || strings.Contains(strings.ToLower(url), textLower) {
+				items = append(items, item)
 			}
-			items = append(items, item)
 		}
 		a.items = items
 		a.fillList()
 		return
 	}
 
-	// If folder selected, filter items within that folder
-	textLower := strings.ToLower(text)
-	var filtered []models.Item
-	for _, item := range a.allItems {
-		itemNameLower := strings.ToLower(item.Name)
+	// Search bookmarks via the repository search (full-text index when available);
+	// without a selected folder this searches all bookmarks
+	bookmarks, err := a.bookmarkSvc.SearchInFolder(text, a.selectedFolder)
+	if err != nil {
+		a.items = []models.Item{}
+		a.fillList()
+		return
+	}
 
-		// Check name
-		if strings.Contains(itemNameLower, textLower) {
-			filtered = append(filtered, item)
-			continue
-		}
+	var items []models.Item
 
-		// For bookmarks also check URL and description
-		if item.Type == models.ItemTypeBookmark {
-			if item.URL != nil {
-				urlLower := strings.ToLower(*item.URL)
-				if strings.Contains(urlLower, textLower) {
-					filtered = append(filtered, item)
-					continue
-				}
-			}
-			if item.Description != nil {
-				descLower := strings.ToLower(*item.Description)
-				if strings.Contains(descLower, textLower) {
-					filtered = append(filtered, item)
-					continue
-				}
+	// Subfolders of the selected folder are matched by name
+	if a.selectedFolder != nil {
+		textLower := strings.ToLower(text)
+		for _, item := range a.allItems {
+			if item.Type == models.ItemTypeFolder && strings.Contains(strings.ToLower(item.Name), textLower) {
+				items = append(items, item)
 			}
 		}
 	}
-	a.items = filtered
+
+	// Convert bookmarks to Items
+	for i := range bookmarks {
+		b := &bookmarks[i]
+		item := models.Item{
+			Type:          models.ItemTypeBookmark,
+			ID:            b.ID,
+			Name:          b.Title,
+			URL:           &b.URL,
+			Description:   &b.Description,
+			Icon:          b.Icon,
+			Keyword:       b.Keyword,
+			ReadLater:     b.ReadLater,
+			ParentID:      b.FolderID,
+			Tags:          b.Tags,
+			CreatedAt:     b.CreatedAt,
+			UpdatedAt:     b.UpdatedAt,
+			LastVisitedAt: b.LastVisitedAt,
+		}
+		items = append(items, item)
+	}
+	a.items = items
 	a.fillList()
 }
 
@@ -264,6 +299,7 @@ func (a *App) onFolderSelect(item folderItem) {
 		newSelectedFolder = nil
 	}
 	a.selectedFolder = newSelectedFolder
+	a.showTrash = item.Trash
 
 	// Sync folder list selection (updates title and selection)
 	a.syncFolderListSelection()
@@ -287,7 +323,34 @@ func (a *App) onFolderSelect(item folderItem) {
 	// UI will update automatically on the next event processing cycle
 }
 
+// sortItems orders the displayed items by the current sort mode,
+// always keeping folders before bookmarks
+func (a *App) sortItems() {
+	if a.sortMode == sortDefault {
+		return
+	}
+
+	itemTime := func(item *models.Item) time.Time {
+		switch a.sortMode {
+		case sortCreated:
+			return item.CreatedAt
+		case sortUpdated:
+			return item.UpdatedAt
+		default:
+			return item.LastVisitedAt
+		}
+	}
+
+	sort.SliceStable(a.items, func(i, j int) bool {
+		if a.items[i].Type != a.items[j].Type {
+			return a.items[i].Type == models.ItemTypeFolder
+		}
+		return itemTime(&a.items[i]).After(itemTime(&a.items[j]))
+	})
+}
+
 func (a *App) fillList() {
+	a.sortItems()
 	a.list.Clear()
 	for i := range a.items {
 		index := i
@@ -302,6 +365,9 @@ func (a *App) fillList() {
 		} else {
 			// For bookmarks show name and URL
 			mainText = item.Name
+			if item.ReadLater {
+				mainText = "📌 " + mainText
+			}
 			if item.URL != nil {
 				secondaryText = *item.URL
 			}
@@ -353,12 +419,18 @@ func (a *App) convertItemToBookmark(item *models.Item) {
 	}
 
 	bookmark := models.Bookmark{
-		ID:          item.ID,
-		Title:       item.Name,
-		URL:         "",
-		Description: "",
-		FolderID:    item.ParentID,
-		FolderName:  folderName,
+		ID:            item.ID,
+		Title:         item.Name,
+		URL:           "",
+		Description:   "",
+		Keyword:       item.Keyword,
+		ReadLater:     item.ReadLater,
+		FolderID:      item.ParentID,
+		FolderName:    folderName,
+		Tags:          item.Tags,
+		CreatedAt:     item.CreatedAt,
+		UpdatedAt:     item.UpdatedAt,
+		LastVisitedAt: item.LastVisitedAt,
 	}
 
 	if item.URL != nil {
@@ -447,6 +519,11 @@ func (a *App) fillFolderList() error {
 	// Start from root level (parentID = nil)
 	buildList(nil, 1)
 
+	// Add "Trash" virtual folder at the end
+	trashItem := folderItem{ID: nil, Name: "🗑 Trash", Level: 0, Trash: true}
+	a.folderItems = append(a.folderItems, trashItem)
+	a.folderList.AddItem(trashItem.Name, "", 0, nil)
+
 	a.folderList.SetTitle("Folders (All)")
 	// Sync folder list selection with current selected folder
 	a.syncFolderListSelection()
@@ -458,7 +535,9 @@ func (a *App) syncFolderListSelection() {
 	var targetIndex int = 0 // Default to "All Bookmarks"
 	found := false
 
-	if a.selectedFolder != nil {
+	if a.showTrash {
+		targetIndex = len(a.folderItems) - 1
+	} else if a.selectedFolder != nil {
 		// Find the index of the folder in folderItems
 		for i, item := range a.folderItems {
 			if item.ID != nil && *item.ID == *a.selectedFolder {
@@ -481,7 +560,9 @@ func (a *App) syncFolderListSelection() {
 	}
 
 	// Update folder list title
-	if a.selectedFolder == nil {
+	if a.showTrash {
+		a.folderList.SetTitle("Folders (Trash)")
+	} else if a.selectedFolder == nil {
 		a.folderList.SetTitle("Folders (All)")
 	} else {
 		// Find folder name
@@ -513,8 +594,8 @@ func (a *App) showDetails() {
 			}
 		}
 		text = fmt.Sprintf(
-			"[::b]Type:[::-]\nFolder\n\n[::b]Name:[::-]\n%s\n\n[::b]Parent:[::-]\n%s",
-			item.Name, parentName)
+			"[::b]Type:[::-]\nFolder\n\n[::b]Name:[::-]\n%s\n\n[::b]Parent:[::-]\n%s\n\n[::b]Created:[::-]\n%s\n\n[::b]Updated:[::-]\n%s",
+			item.Name, parentName, formatTime(item.CreatedAt), formatTime(item.UpdatedAt))
 	} else {
 		// Show bookmark information
 		b := a.current
@@ -536,22 +617,57 @@ func (a *App) showDetails() {
 				}
 			}
 			text = fmt.Sprintf(
-				"[::b]Type:[::-]\nBookmark\n\n[::b]Title:[::-]\n%s\n\n[::b]URL:[::-]\n%s\n\n[::b]Description:[::-]\n%s\n\n[::b]Folder:[::-]\n%s",
-				item.Name, url, desc, folderName)
+				"[::b]Type:[::-]\nBookmark\n\n[::b]Title:[::-]\n%s\n\n[::b]URL:[::-]\n%s\n\n[::b]Description:[::-]\n%s\n\n[::b]Tags:[::-]\n%s\n\n[::b]Folder:[::-]\n%s",
+				item.Name, url, desc, strings.Join(item.Tags, ", "), folderName)
+			text += readingDetails(item.Keyword, item.ReadLater)
+			text += timestampDetails(item.CreatedAt, item.UpdatedAt, item.LastVisitedAt)
 		} else {
 			folderName := "/"
 			if b.FolderName != nil {
 				folderName = *b.FolderName
 			}
 			text = fmt.Sprintf(
-				"[::b]Type:[::-]\nBookmark\n\n[::b]Title:[::-]\n%s\n\n[::b]URL:[::-]\n%s\n\n[::b]Description:[::-]\n%s\n\n[::b]Folder:[::-]\n%s",
-				b.Title, b.URL, b.Description, folderName)
+				"[::b]Type:[::-]\nBookmark\n\n[::b]Title:[::-]\n%s\n\n[::b]URL:[::-]\n%s\n\n[::b]Description:[::-]\n%s\n\n[::b]Tags:[::-]\n%s\n\n[::b]Folder:[::-]\n%s",
+				b.Title, b.URL, b.Description, strings.Join(b.Tags, ", "), folderName)
+			text += readingDetails(b.Keyword, b.ReadLater)
+			text += timestampDetails(b.CreatedAt, b.UpdatedAt, b.LastVisitedAt)
 		}
 	}
 
+	if !item.DeletedAt.IsZero() {
+		text += fmt.Sprintf("\n\n[::b]Deleted:[::-]\n%s", formatTime(item.DeletedAt))
+	}
+
 	a.detail.SetText(text)
 }
 
+// readingDetails formats the keyword and read-later flag for the details pane
+func readingDetails(keyword string, readLater bool) string {
+	if keyword == "" {
+		keyword = "-"
+	}
+	later := "no"
+	if readLater {
+		later = "yes"
+	}
+	return fmt.Sprintf("\n\n[::b]Keyword:[::-]\n%s\n\n[::b]Read later:[::-]\n%s", keyword, later)
+}
+
+// timestampDetails formats bookmark timestamps for the details pane
+func timestampDetails(created, updated, visited time.Time) string {
+	return fmt.Sprintf(
+		"\n\n[::b]Created:[::-]\n%s\n\n[::b]Updated:[::-]\n%s\n\n[::b]Last visited:[::-]\n%s",
+		formatTime(created), formatTime(updated), formatTime(visited))
+}
+
+// formatTime formats a timestamp in local time, or "-" if it is unknown
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
 func (a *App) setMode(m uint8) {
 	a.mode = m
 	switch m {
@@ -580,6 +696,8 @@ func (a *App) toggleFocus() {
 					break
 				}
 			}
+		} else if a.showTrash {
+			a.folderList.SetTitle("Folders (Trash)")
 		} else {
 			a.folderList.SetTitle("Folders (All)")
 		}
@@ -593,6 +711,8 @@ func (a *App) toggleFocus() {
 					break
 				}
 			}
+		} else if a.showTrash {
+			a.folderList.SetTitle("Folders (Trash)")
 		} else {
 			a.folderList.SetTitle("Folders (All)")
 		}
@@ -706,16 +826,7 @@ func (a *App) globalInput(event *tcell.EventKey) *tcell.EventKey {
 					if currentIndex >= 0 && currentIndex < len(a.folderItems) {
 						item := a.folderItems[currentIndex]
 						if item.ID != nil {
-							// Show confirmation before deletion
-							confirmMessage := fmt.Sprintf("Are you sure you want to delete folder '%s'?", item.Name)
-							a.showConfirm(confirmMessage, func() {
-								if err := a.folderSvc.Delete(*item.ID); err != nil {
-									a.showError(fmt.Sprintf("Error deleting folder: %v", err))
-								} else {
-									a.reloadFolders()
-									a.reloadBookmarks() // Reload bookmarks as they may be in deleted folder
-								}
-							})
+							a.confirmDeleteFolder(*item.ID, item.Name)
 						}
 					}
 					return nil
@@ -725,6 +836,11 @@ func (a *App) globalInput(event *tcell.EventKey) *tcell.EventKey {
 			return event
 		}
 
+		// Trash has its own commands, items there can't be opened or edited
+		if a.showTrash {
+			return a.trashInput(event)
+		}
+
 		// If focus on item list, handle normal commands
 		switch event.Key() {
 		case tcell.KeyEnter:
@@ -733,6 +849,14 @@ func (a *App) globalInput(event *tcell.EventKey) *tcell.EventKey {
 					// Open bookmark
 					if a.currentItem.URL != nil && *a.currentItem.URL != "" {
 						openURL(*a.currentItem.URL)
+						// Record the visit; failing to do so shouldn't get in the way
+						if err := a.bookmarkSvc.MarkVisited(a.currentItem.ID); err == nil {
+							a.currentItem.LastVisitedAt = time.Now()
+							if a.current != nil {
+								a.current.LastVisitedAt = a.currentItem.LastVisitedAt
+							}
+							a.showDetails()
+						}
 					}
 				} else if a.currentItem.Type == models.ItemTypeFolder {
 					// Navigate into folder
@@ -754,6 +878,14 @@ func (a *App) globalInput(event *tcell.EventKey) *tcell.EventKey {
 			case '/':
 				a.setMode(ModeSearch)
 				return nil
+			case 's':
+				// Cycle sort order; reload to restore the default order
+				a.sortMode = (a.sortMode + 1) % sortMode(len(sortModeNames))
+				if err := a.loadFolderContent(); err != nil {
+					a.showError(fmt.Sprintf("Error loading items: %v", err))
+				}
+				a.updateStatus()
+				return nil
 			case 'a':
 				// Create new bookmark
 				newBookmark := models.Bookmark{}
@@ -794,7 +926,7 @@ func (a *App) globalInput(event *tcell.EventKey) *tcell.EventKey {
 							a.convertItemToBookmark(a.currentItem)
 						}
 						if a.current != nil {
-							confirmMessage := fmt.Sprintf("Are you sure you want to delete bookmark '%s'?", a.current.Title)
+							confirmMessage := fmt.Sprintf("Move bookmark '%s' to the trash?", a.current.Title)
 							a.showConfirm(confirmMessage, func() {
 								if err := a.bookmarkSvc.Delete(a.current.ID); err != nil {
 									a.showError(fmt.Sprintf("Error deleting bookmark: %v", err))
@@ -805,18 +937,15 @@ func (a *App) globalInput(event *tcell.EventKey) *tcell.EventKey {
 						}
 					} else if a.currentItem.Type == models.ItemTypeFolder {
 						// Delete folder
-						confirmMessage := fmt.Sprintf("Are you sure you want to delete folder '%s'?", a.currentItem.Name)
-						a.showConfirm(confirmMessage, func() {
-							if err := a.folderSvc.Delete(a.currentItem.ID); err != nil {
-								a.showError(fmt.Sprintf("Error deleting folder: %v", err))
-							} else {
-								a.reloadFolders()
-								a.reloadBookmarks()
-							}
-						})
+						a.confirmDeleteFolder(a.currentItem.ID, a.currentItem.Name)
 					}
 				}
 				return nil
+			case 'l':
+				if a.currentItem != nil && a.currentItem.Type == models.ItemTypeBookmark {
+					a.toggleReadLater(a.currentItem.ID)
+				}
+				return nil
 			case 'q':
 				a.app.Stop()
 				return nil
@@ -833,10 +962,85 @@ func (a *App) globalInput(event *tcell.EventKey) *tcell.EventKey {
 	return event
 }
 
+// toggleReadLater marks a bookmark to read later or unmarks it, keeping
+// the selection
+func (a *App) toggleReadLater(id int) {
+	b, err := a.bookmarkSvc.GetByID(id)
+	if err != nil {
+		a.showError(fmt.Sprintf("Error loading bookmark: %v", err))
+		return
+	}
+	if b == nil {
+		return
+	}
+	b.ReadLater = !b.ReadLater
+	if err := a.bookmarkSvc.Update(b); err != nil {
+		a.showError(fmt.Sprintf("Error saving bookmark: %v", err))
+		return
+	}
+	index := a.list.GetCurrentItem()
+	a.reloadBookmarks()
+	a.list.SetCurrentItem(index)
+}
+
+// trashInput handles keys of the item list while it shows the trash
+func (a *App) trashInput(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() != tcell.KeyRune {
+		if event.Key() == tcell.KeyEnter {
+			return nil
+		}
+		return event
+	}
+
+	switch event.Rune() {
+	case '/':
+		a.setMode(ModeSearch)
+	case 's':
+		a.sortMode = (a.sortMode + 1) % sortMode(len(sortModeNames))
+		if err := a.loadFolderContent(); err != nil {
+			a.showError(fmt.Sprintf("Error loading items: %v", err))
+		}
+		a.updateStatus()
+	case 'r':
+		if a.currentItem != nil {
+			item := *a.currentItem
+			if err := a.trashSvc.Restore(&item); err != nil {
+				a.showError(fmt.Sprintf("Error restoring %s: %v", item.Type, err))
+				return nil
+			}
+			if item.Type == models.ItemTypeFolder || item.ParentID != nil {
+				// Restoring may bring back parent folders too
+				a.reloadFolders()
+			}
+			a.reloadBookmarks()
+			a.updateStatus()
+		}
+	case 'd':
+		if a.currentItem != nil {
+			item := *a.currentItem
+			confirmMessage := fmt.Sprintf("Permanently delete %s '%s'? This cannot be undone.", item.Type, item.Name)
+			a.showConfirm(confirmMessage, func() {
+				if err := a.trashSvc.Purge(&item); err != nil {
+					a.showError(fmt.Sprintf("Error deleting %s: %v", item.Type, err))
+				} else {
+					a.reloadBookmarks()
+					a.updateStatus()
+				}
+			})
+		}
+	case 'q':
+		a.app.Stop()
+	default:
+		return event
+	}
+	return nil
+}
+
 func (a *App) showForm(b *models.Bookmark, edit bool) {
 	title := b.Title
 	url := b.URL
 	desc := b.Description
+	tags := strings.Join(b.Tags, ", ")
 
 	// Get list of all folders for dropdown
 	folders, err := a.folderSvc.ListAll()
@@ -881,6 +1085,9 @@ func (a *App) showForm(b *models.Bookmark, edit bool) {
 	form.AddInputField("Title", title, 60, nil, func(t string) { b.Title = t })
 	form.AddInputField("URL", url, 60, nil, func(t string) { b.URL = t })
 	form.AddInputField("Description", desc, 60, nil, func(t string) { b.Description = t })
+	form.AddInputField("Tags", tags, 60, nil, func(t string) { b.Tags = service.ParseTags(t) })
+	form.AddInputField("Keyword", b.Keyword, 20, nil, func(t string) { b.Keyword = strings.TrimSpace(t) })
+	form.AddCheckbox("Read later", b.ReadLater, func(checked bool) { b.ReadLater = checked })
 
 	// Add dropdown for folder selection
 	form.AddDropDown("Folder", folderOptions, selectedIndex, func(option string, index int) {
@@ -1066,14 +1273,22 @@ func (a *App) showError(message string) {
 }
 
 func (a *App) showConfirm(message string, onConfirm func()) {
+	a.showChoice(message, []string{"Cancel", "OK"}, func(buttonIndex int) {
+		if buttonIndex == 1 && onConfirm != nil {
+			onConfirm()
+		}
+	})
+}
+
+// showChoice shows a modal with several buttons. onChoice receives the index
+// of the pressed button, or -1 if the modal was closed with Esc.
+func (a *App) showChoice(message string, buttons []string, onChoice func(buttonIndex int)) {
 	modal := tview.NewModal().
 		SetText(message).
-		AddButtons([]string{"Cancel", "OK"}).
+		AddButtons(buttons).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			a.pages.RemovePage("confirm")
-			if buttonIndex == 1 && onConfirm != nil {
-				onConfirm()
-			}
+			onChoice(buttonIndex)
 			// Restore mode and focus
 			if a.pages.HasPage("form") || a.pages.HasPage("folderForm") {
 				a.mode = ModeForm
@@ -1093,6 +1308,42 @@ func (a *App) showConfirm(message string, onConfirm func()) {
 	a.app.SetFocus(modal)
 }
 
+// confirmDeleteFolder asks what to do with the contents of a folder before moving it to the trash
+func (a *App) confirmDeleteFolder(id int, name string) {
+	deleteFolder := func(policy models.FolderDeletePolicy) {
+		if err := a.folderSvc.Delete(id, policy); err != nil {
+			a.showError(fmt.Sprintf("Error deleting folder: %v", err))
+			return
+		}
+		a.reloadFolders()
+		a.reloadBookmarks() // Reload bookmarks as they may be in deleted folder
+		a.updateStatus()
+	}
+
+	bookmarks, folders, err := a.folderSvc.CountContents(id)
+	if err != nil {
+		a.showError(fmt.Sprintf("Error reading folder: %v", err))
+		return
+	}
+	if bookmarks == 0 && folders == 0 {
+		a.showConfirm(fmt.Sprintf("Move empty folder '%s' to the trash?", name), func() {
+			deleteFolder(models.FolderDeleteCascade)
+		})
+		return
+	}
+
+	message := fmt.Sprintf("Folder '%s' contains %d bookmarks and %d subfolders.\n"+
+		"Move them to the parent folder, or move everything to the trash?", name, bookmarks, folders)
+	a.showChoice(message, []string{"Cancel", "Move to parent", "Trash all"}, func(buttonIndex int) {
+		switch buttonIndex {
+		case 1:
+			deleteFolder(models.FolderDeleteMoveToParent)
+		case 2:
+			deleteFolder(models.FolderDeleteCascade)
+		}
+	})
+}
+
 func openURL(url string) {
 	var cmd string
 	var args []string